@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"path"
 	"time"
@@ -14,16 +15,13 @@ import (
 
 	"github.com/l3uddz/tqm/config"
 	"github.com/l3uddz/tqm/logger"
+	"github.com/l3uddz/tqm/tracker"
 )
 
 /* Struct */
 
 type Deluge struct {
-	Host     *string `validate:"required"`
-	Port     *uint   `validate:"required"`
-	Login    *string `validate:"required"`
-	Password *string `validate:"required"`
-	V2       bool
+	cfg config.DelugeConfiguration
 
 	// internal
 	log        *logrus.Entry
@@ -49,25 +47,22 @@ func NewDeluge(name string, exp *expression.Expressions) (Interface, error) {
 		exp:        exp,
 	}
 
-	// load config
-	if err := config.K.Unmarshal(fmt.Sprintf("clients%s%s", config.Delimiter, name), &tc); err != nil {
-		return nil, fmt.Errorf("unmarshal config: %w", err)
-	}
-
-	// validate config
-	if errs := config.ValidateStruct(tc); errs != nil {
-		return nil, fmt.Errorf("validate config: %v", errs)
+	// load and validate config
+	if err := config.UnmarshalClientConfiguration(name, &tc.cfg); err != nil {
+		return nil, err
 	}
 
 	// init client
+	_, requestTimeout := tc.cfg.Timeouts()
 	settings := delugeclient.Settings{
-		Hostname: *tc.Host,
-		Port:     *tc.Port,
-		Login:    *tc.Login,
-		Password: *tc.Password,
+		Hostname:         tc.cfg.Host,
+		Port:             tc.cfg.Port,
+		Login:            tc.cfg.Login,
+		Password:         tc.cfg.Password,
+		ReadWriteTimeout: requestTimeout,
 	}
 
-	if tc.V2 {
+	if tc.cfg.V2 {
 		tc.client2 = delugeclient.NewV2(settings)
 	} else {
 		tc.client1 = delugeclient.NewV1(settings)
@@ -82,13 +77,21 @@ func (c *Deluge) Type() string {
 	return c.clientType
 }
 
-func (c *Deluge) Connect() error {
+func (c *Deluge) Connect(ctx context.Context) error {
+	connectTimeout, _ := c.cfg.Timeouts()
+	ctx, cancel := context.WithTimeout(ctx, connectTimeout)
+	defer cancel()
+
+	return runContext(ctx, c.connect)
+}
+
+func (c *Deluge) connect() error {
 	var err error
 
 	// connect to deluge daemon
-	c.log.Tracef("Connecting to %s:%d", *c.Host, *c.Port)
+	c.log.Tracef("Connecting to %s:%d", c.cfg.Host, c.cfg.Port)
 
-	if c.V2 {
+	if c.cfg.V2 {
 		err = c.client2.Connect()
 	} else {
 		err = c.client1.Connect()
@@ -102,7 +105,7 @@ func (c *Deluge) Connect() error {
 	// retrieve & set common label client
 	var lc *delugeclient.LabelPlugin
 
-	if c.V2 {
+	if c.cfg.V2 {
 		lc, err = c.client2.LabelPlugin()
 	} else {
 		lc, err = c.client1.LabelPlugin()
@@ -123,18 +126,26 @@ func (c *Deluge) Connect() error {
 	return nil
 }
 
-func (c *Deluge) GetTorrents() (map[string]config.Torrent, error) {
+func (c *Deluge) GetTorrents(ctx context.Context) (map[string]config.Torrent, error) {
 	// retrieve torrents from client
 	c.log.Tracef("Retrieving torrents...")
-	t, err := c.client.TorrentsStatus(delugeclient.StateUnspecified, nil)
-	if err != nil {
+	var t map[string]*delugeclient.TorrentStatus
+	if err := runContext(ctx, func() error {
+		var err error
+		t, err = c.client.TorrentsStatus(delugeclient.StateUnspecified, nil)
+		return err
+	}); err != nil {
 		return nil, fmt.Errorf("get torrents: %w", err)
 	}
 	c.log.Tracef("Retrieved %d torrents", len(t))
 
 	// retrieve torrent labels
-	labels, err := c.client.GetTorrentsLabels(delugeclient.StateUnspecified, nil)
-	if err != nil {
+	var labels map[string]string
+	if err := runContext(ctx, func() error {
+		var err error
+		labels, err = c.client.GetTorrentsLabels(delugeclient.StateUnspecified, nil)
+		return err
+	}); err != nil {
 		return nil, fmt.Errorf("get torrent labels: %w", err)
 	}
 	c.log.Tracef("Retrieved labels for %d torrents", len(labels))
@@ -183,7 +194,8 @@ func (c *Deluge) GetTorrents() (map[string]config.Torrent, error) {
 			FreeSpaceGB:  c.GetFreeSpace,
 			FreeSpaceSet: c.freeSpaceSet,
 			// tracker
-			TrackerName:   t.TrackerHost,
+			TrackerName:   tracker.CanonicalName(t.TrackerHost, t.TrackerHost),
+			TrackerHost:   t.TrackerHost,
 			TrackerStatus: t.TrackerStatus,
 		}
 
@@ -193,52 +205,38 @@ func (c *Deluge) GetTorrents() (map[string]config.Torrent, error) {
 	return torrents, nil
 }
 
-func (c *Deluge) RemoveTorrent(hash string, deleteData bool) (bool, error) {
-	// pause torrent
-	if err := c.client.PauseTorrents(hash); err != nil {
-		return false, fmt.Errorf("pause torrent: %v: %w", hash, err)
-	}
-
-	time.Sleep(1 * time.Second)
-
-	// resume torrent
-	if err := c.client.ResumeTorrents(hash); err != nil {
-		return false, fmt.Errorf("resume torrent: %v: %w", hash, err)
+func (c *Deluge) RemoveTorrents(ctx context.Context, hashes []string, deleteData bool,
+	strategy config.RemovalStrategy) error {
+	// bound the removal by the duration of its steps (deluge v1 removes each torrent with a separate request)
+	_, requestTimeout := c.cfg.Timeouts()
+	timeout := strategy.Timeout(requestTimeout)
+	if !c.cfg.V2 && len(hashes) > 1 {
+		timeout += time.Duration(len(hashes)-1) * requestTimeout
 	}
 
-	// sleep before re-announcing torrent
-	time.Sleep(2 * time.Second)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	// re-announce torrent
-	if err := c.client.ForceReannounce([]string{hash}); err != nil {
-		return false, fmt.Errorf("re-announce torrent: %v: %w", hash, err)
-	}
-
-	// sleep before removing torrent
-	time.Sleep(2 * time.Second)
-
-	// remove
-	if ok, err := c.client.RemoveTorrent(hash, deleteData); err != nil {
-		return false, fmt.Errorf("remove torrent: %v: %w", hash, err)
-	} else if !ok {
-		return false, fmt.Errorf("remove torrent: %v", hash)
-	}
-
-	return true, nil
+	return removeTorrents(ctx, c, hashes, deleteData, strategy)
 }
 
-func (c *Deluge) SetTorrentLabel(hash string, label string) error {
+func (c *Deluge) SetTorrentLabel(ctx context.Context, hash string, label string) error {
 	// set label
-	if err := c.client.SetTorrentLabel(hash, label); err != nil {
+	if err := runContext(ctx, func() error { return c.client.SetTorrentLabel(hash, label) }); err != nil {
 		return fmt.Errorf("set torrent label: %v: %w", label, err)
 	}
 
 	return nil
 }
 
-func (c *Deluge) GetCurrentFreeSpace(path string) (int64, error) {
+func (c *Deluge) GetCurrentFreeSpace(ctx context.Context, path string) (int64, error) {
 	// get free disk space
-	space, err := c.client.GetFreeSpace(path)
+	var space int64
+	err := runContext(ctx, func() error {
+		var err error
+		space, err = c.client.GetFreeSpace(path)
+		return err
+	})
 	if err != nil {
 		return 0, fmt.Errorf("get free disk space: %v: %w", path, err)
 	}
@@ -258,39 +256,88 @@ func (c *Deluge) GetFreeSpace() float64 {
 	return c.freeSpaceGB
 }
 
+/* Removal */
+
+func (c *Deluge) pause(ctx context.Context, hashes []string) error {
+	return runContext(ctx, func() error { return c.client.PauseTorrents(hashes...) })
+}
+
+func (c *Deluge) resume(ctx context.Context, hashes []string) error {
+	return runContext(ctx, func() error { return c.client.ResumeTorrents(hashes...) })
+}
+
+func (c *Deluge) reannounce(ctx context.Context, hashes []string) error {
+	return runContext(ctx, func() error { return c.client.ForceReannounce(hashes) })
+}
+
+func (c *Deluge) remove(ctx context.Context, hashes []string, deleteData bool) error {
+	// removing multiple torrents at once is only supported by deluge v2
+	if c.cfg.V2 {
+		var torrentErrors []delugeclient.TorrentError
+		if err := runContext(ctx, func() error {
+			var err error
+			torrentErrors, err = c.client.RemoveTorrents(hashes, deleteData)
+			return err
+		}); err != nil {
+			return err
+		}
+
+		if len(torrentErrors) == 0 {
+			return nil
+		}
+
+		errs := make(TorrentErrors)
+		for _, e := range torrentErrors {
+			errs[e.ID] = fmt.Errorf("remove torrent: %s", e.Message)
+		}
+		return errs
+	}
+
+	errs := make(TorrentErrors)
+	for _, hash := range hashes {
+		ok := false
+		if err := runContext(ctx, func() error {
+			var err error
+			ok, err = c.client.RemoveTorrent(hash, deleteData)
+			return err
+		}); err != nil {
+			errs[hash] = fmt.Errorf("remove torrent: %w", err)
+		} else if !ok {
+			errs[hash] = fmt.Errorf("remove torrent: %v", hash)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
 /* Filters */
 
 func (c *Deluge) ShouldIgnore(t *config.Torrent) (bool, error) {
-	match, err := expression.CheckTorrentSingleMatch(t, c.exp.Ignores)
+	d, err := c.exp.CheckIgnore(t)
 	if err != nil {
-		return true, fmt.Errorf("check ignore expression: %v: %w", t.Hash, err)
+		return true, fmt.Errorf("%v: %w", t.Hash, err)
 	}
 
-	return match, nil
+	return d.Action == expression.ActionIgnore, nil
 }
 
 func (c *Deluge) ShouldRemove(t *config.Torrent) (bool, error) {
-	match, err := expression.CheckTorrentSingleMatch(t, c.exp.Removes)
+	d, err := c.exp.CheckRemove(t)
 	if err != nil {
-		return false, fmt.Errorf("check remove expression: %v: %w", t.Hash, err)
+		return false, fmt.Errorf("%v: %w", t.Hash, err)
 	}
 
-	return match, nil
+	return d.Action == expression.ActionRemove, nil
 }
 
 func (c *Deluge) ShouldRelabel(t *config.Torrent) (string, bool, error) {
-	for _, label := range c.exp.Labels {
-		// check update
-		match, err := expression.CheckTorrentAllMatch(t, label.Updates)
-		if err != nil {
-			return "", false, fmt.Errorf("check update expression: %v: %w", t.Hash, err)
-		} else if !match {
-			continue
-		}
-
-		// we should re-label
-		return label.Name, true, nil
+	d, err := c.exp.CheckLabel(t)
+	if err != nil {
+		return "", false, fmt.Errorf("%v: %w", t.Hash, err)
 	}
 
-	return "", false, nil
+	return d.Label, d.Action == expression.ActionLabel, nil
 }
@@ -1,16 +1,18 @@
 package client
 
 import (
+	"context"
+
 	"github.com/l3uddz/tqm/config"
 )
 
 type Interface interface {
 	Type() string
-	Connect() error
-	GetTorrents() (map[string]config.Torrent, error)
-	RemoveTorrent(string, bool) (bool, error)
-	SetTorrentLabel(string, string) error
-	GetCurrentFreeSpace(string) (int64, error)
+	Connect(context.Context) error
+	GetTorrents(context.Context) (map[string]config.Torrent, error)
+	RemoveTorrents(context.Context, []string, bool, config.RemovalStrategy) error
+	SetTorrentLabel(context.Context, string, string) error
+	GetCurrentFreeSpace(context.Context, string) (int64, error)
 	AddFreeSpace(int64)
 	GetFreeSpace() float64
 
@@ -1,14 +1,17 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/dustin/go-humanize"
 	"github.com/l3uddz/go-qbt"
+	"github.com/l3uddz/go-qbt/pkg/model"
 	"github.com/sirupsen/logrus"
 
 	"github.com/l3uddz/tqm/config"
@@ -16,19 +19,19 @@ import (
 	"github.com/l3uddz/tqm/logger"
 	"github.com/l3uddz/tqm/sliceutils"
 	"github.com/l3uddz/tqm/stringutils"
+	"github.com/l3uddz/tqm/tracker"
 )
 
 /* Struct */
 
 type QBittorrent struct {
-	Url      *string `validate:"required"`
-	User     string
-	Password string
+	cfg config.QBittorrentConfiguration
 
 	// internal
 	log        *logrus.Entry
 	clientType string
 	client     *qbittorrent.Client
+	transport  *contextTransport
 
 	// set by cmd handler
 	freeSpaceGB  float64
@@ -47,20 +50,21 @@ func NewQBittorrent(name string, exp *expression.Expressions) (Interface, error)
 		exp:        exp,
 	}
 
-	// load config
-	if err := config.K.Unmarshal(fmt.Sprintf("clients%s%s", config.Delimiter, name), &tc); err != nil {
-		return nil, fmt.Errorf("unmarshal config: %w", err)
-	}
-
-	// validate config
-	if errs := config.ValidateStruct(tc); errs != nil {
-		return nil, fmt.Errorf("validate config: %v", errs)
+	// load and validate config
+	if err := config.UnmarshalClientConfiguration(name, &tc.cfg); err != nil {
+		return nil, err
 	}
 
 	// init client
 	qbl := logrus.New()
 	qbl.Out = ioutil.Discard
-	tc.client = qbittorrent.NewClient(strings.TrimSuffix(*tc.Url, "/"), qbl)
+	tc.client = qbittorrent.NewClient(strings.TrimSuffix(tc.cfg.Url, "/"), qbl)
+
+	// apply timeouts and the context of each operation to the api requests (the http client is shared by the api)
+	_, requestTimeout := tc.cfg.Timeouts()
+	tc.transport = &contextTransport{base: http.DefaultTransport}
+	tc.client.Torrent.Client.Timeout = requestTimeout
+	tc.client.Torrent.Client.Transport = tc.transport
 
 	return &tc, nil
 }
@@ -71,25 +75,42 @@ func (c *QBittorrent) Type() string {
 	return c.clientType
 }
 
-func (c *QBittorrent) Connect() error {
-	// login
-	if err := c.client.Login(c.User, c.Password); err != nil {
-		return fmt.Errorf("login: %w", err)
-	}
+func (c *QBittorrent) Connect(ctx context.Context) error {
+	connectTimeout, _ := c.cfg.Timeouts()
+	ctx, cancel := context.WithTimeout(ctx, connectTimeout)
+	defer cancel()
 
-	// retrieve & validate api version
-	apiVersion, err := c.client.Application.GetAPIVersion()
-	if err != nil {
-		return fmt.Errorf("get api version: %w", err)
-	} else if stringutils.Atof64(apiVersion[0:3], 0.0) < 2.2 {
-		return fmt.Errorf("unsupported webapi version: %v", apiVersion)
-	}
+	return c.transport.do(ctx, func() error {
+		// login
+		if err := c.client.Login(c.cfg.User, c.cfg.Password); err != nil {
+			return fmt.Errorf("login: %w", err)
+		}
+
+		// retrieve & validate api version
+		apiVersion, err := c.client.Application.GetAPIVersion()
+		if err != nil {
+			return fmt.Errorf("get api version: %w", err)
+		} else if stringutils.Atof64(apiVersion[0:3], 0.0) < 2.2 {
+			return fmt.Errorf("unsupported webapi version: %v", apiVersion)
+		}
 
-	c.log.Debugf("API Version: %v", apiVersion)
-	return nil
+		c.log.Debugf("API Version: %v", apiVersion)
+		return nil
+	})
 }
 
-func (c *QBittorrent) GetTorrents() (map[string]config.Torrent, error) {
+func (c *QBittorrent) GetTorrents(ctx context.Context) (map[string]config.Torrent, error) {
+	var torrents map[string]config.Torrent
+	err := c.transport.do(ctx, func() error {
+		var err error
+		torrents, err = c.getTorrents(ctx)
+		return err
+	})
+
+	return torrents, err
+}
+
+func (c *QBittorrent) getTorrents(ctx context.Context) (map[string]config.Torrent, error) {
 	// retrieve torrents from client
 	c.log.Tracef("Retrieving torrents...")
 	t, err := c.client.Torrent.GetList(nil)
@@ -103,6 +124,10 @@ func (c *QBittorrent) GetTorrents() (map[string]config.Torrent, error) {
 	for _, t := range t {
 		t := t
 
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		// get additional torrent details
 		td, err := c.client.Torrent.GetProperties(t.Hash)
 		if err != nil {
@@ -121,18 +146,20 @@ func (c *QBittorrent) GetTorrents() (map[string]config.Torrent, error) {
 
 		// parse tracker details
 		trackerName := ""
+		trackerHost := ""
 		trackerStatus := ""
 
-		for _, tracker := range ts {
+		for _, tr := range ts {
 			// skip disabled trackers
-			if strings.Contains(tracker.URL, "[DHT]") || strings.Contains(tracker.URL, "[LSD]") ||
-				strings.Contains(tracker.URL, "[PeX]") {
+			if strings.Contains(tr.URL, "[DHT]") || strings.Contains(tr.URL, "[LSD]") ||
+				strings.Contains(tr.URL, "[PeX]") {
 				continue
 			}
 
 			// use status of first enabled tracker
-			trackerName = parseTrackerDomain(tracker.URL)
-			trackerStatus = tracker.Message
+			trackerHost = parseTrackerHost(tr.URL)
+			trackerName = tracker.CanonicalName(trackerHost, parseTrackerDomain(tr.URL))
+			trackerStatus = tr.Message
 			break
 		}
 
@@ -180,6 +207,7 @@ func (c *QBittorrent) GetTorrents() (map[string]config.Torrent, error) {
 			FreeSpaceSet: c.freeSpaceSet,
 			// tracker
 			TrackerName:   trackerName,
+			TrackerHost:   trackerHost,
 			TrackerStatus: trackerStatus,
 		}
 
@@ -189,49 +217,37 @@ func (c *QBittorrent) GetTorrents() (map[string]config.Torrent, error) {
 	return torrents, nil
 }
 
-func (c *QBittorrent) RemoveTorrent(hash string, deleteData bool) (bool, error) {
-	// pause torrent
-	if err := c.client.Torrent.StopTorrents([]string{hash}); err != nil {
-		return false, fmt.Errorf("pause torrent: %v: %w", hash, err)
-	}
-
-	time.Sleep(1 * time.Second)
-
-	// resume torrent
-	if err := c.client.Torrent.ResumeTorrents([]string{hash}); err != nil {
-		return false, fmt.Errorf("resume torrent: %v: %w", hash, err)
-	}
-
-	// sleep before re-announcing torrent
-	time.Sleep(2 * time.Second)
+func (c *QBittorrent) RemoveTorrents(ctx context.Context, hashes []string, deleteData bool,
+	strategy config.RemovalStrategy) error {
+	// bound the removal by the duration of its steps
+	_, requestTimeout := c.cfg.Timeouts()
+	ctx, cancel := context.WithTimeout(ctx, strategy.Timeout(requestTimeout))
+	defer cancel()
 
-	if err := c.client.Torrent.ReannounceTorrents([]string{hash}); err != nil {
-		return false, fmt.Errorf("re-announce torrent: %v: %w", hash, err)
-	}
-
-	// sleep before removing torrent
-	time.Sleep(2 * time.Second)
-
-	// remove
-	if err := c.client.Torrent.DeleteTorrents([]string{hash}, deleteData); err != nil {
-		return false, fmt.Errorf("delete torrent: %v: %w", hash, err)
-	}
-
-	return true, nil
+	return c.transport.do(ctx, func() error {
+		return removeTorrents(ctx, c, hashes, deleteData, strategy)
+	})
 }
 
-func (c *QBittorrent) SetTorrentLabel(hash string, label string) error {
-	// set label
-	if err := c.client.Torrent.SetCategories([]string{hash}, label); err != nil {
-		return fmt.Errorf("set torrent label: %v: %w", label, err)
-	}
+func (c *QBittorrent) SetTorrentLabel(ctx context.Context, hash string, label string) error {
+	return c.transport.do(ctx, func() error {
+		// set label
+		if err := c.client.Torrent.SetCategories([]string{hash}, label); err != nil {
+			return fmt.Errorf("set torrent label: %v: %w", label, err)
+		}
 
-	return nil
+		return nil
+	})
 }
 
-func (c *QBittorrent) GetCurrentFreeSpace(path string) (int64, error) {
+func (c *QBittorrent) GetCurrentFreeSpace(ctx context.Context, path string) (int64, error) {
 	// get current main stats
-	data, err := c.client.Sync.GetMainData(0)
+	var data *model.SyncMainData
+	err := c.transport.do(ctx, func() error {
+		var err error
+		data, err = c.client.Sync.GetMainData(0)
+		return err
+	})
 	if err != nil {
 		return 0, fmt.Errorf("get main data: %w", err)
 	}
@@ -251,39 +267,49 @@ func (c *QBittorrent) GetFreeSpace() float64 {
 	return c.freeSpaceGB
 }
 
+/* Removal */
+
+func (c *QBittorrent) pause(_ context.Context, hashes []string) error {
+	return c.client.Torrent.StopTorrents(hashes)
+}
+
+func (c *QBittorrent) resume(_ context.Context, hashes []string) error {
+	return c.client.Torrent.ResumeTorrents(hashes)
+}
+
+func (c *QBittorrent) reannounce(_ context.Context, hashes []string) error {
+	return c.client.Torrent.ReannounceTorrents(hashes)
+}
+
+func (c *QBittorrent) remove(_ context.Context, hashes []string, deleteData bool) error {
+	return c.client.Torrent.DeleteTorrents(hashes, deleteData)
+}
+
 /* Filters */
 
 func (c *QBittorrent) ShouldIgnore(t *config.Torrent) (bool, error) {
-	match, err := expression.CheckTorrentSingleMatch(t, c.exp.Ignores)
+	d, err := c.exp.CheckIgnore(t)
 	if err != nil {
-		return true, fmt.Errorf("check ignore expression: %v: %w", t.Hash, err)
+		return true, fmt.Errorf("%v: %w", t.Hash, err)
 	}
 
-	return match, nil
+	return d.Action == expression.ActionIgnore, nil
 }
 
 func (c *QBittorrent) ShouldRemove(t *config.Torrent) (bool, error) {
-	match, err := expression.CheckTorrentSingleMatch(t, c.exp.Removes)
+	d, err := c.exp.CheckRemove(t)
 	if err != nil {
-		return false, fmt.Errorf("check remove expression: %v: %w", t.Hash, err)
+		return false, fmt.Errorf("%v: %w", t.Hash, err)
 	}
 
-	return match, nil
+	return d.Action == expression.ActionRemove, nil
 }
 
 func (c *QBittorrent) ShouldRelabel(t *config.Torrent) (string, bool, error) {
-	for _, label := range c.exp.Labels {
-		// check update
-		match, err := expression.CheckTorrentAllMatch(t, label.Updates)
-		if err != nil {
-			return "", false, fmt.Errorf("check update expression: %v: %w", t.Hash, err)
-		} else if !match {
-			continue
-		}
-
-		// we should re-label
-		return label.Name, true, nil
+	d, err := c.exp.CheckLabel(t)
+	if err != nil {
+		return "", false, fmt.Errorf("%v: %w", t.Hash, err)
 	}
 
-	return "", false, nil
+	return d.Label, d.Action == expression.ActionLabel, nil
 }
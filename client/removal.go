@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/l3uddz/tqm/config"
+)
+
+// TorrentErrors are the errors of the torrents that failed to be removed (by hash), the other torrents were removed
+type TorrentErrors map[string]error
+
+func (e TorrentErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for hash, err := range e {
+		msgs = append(msgs, fmt.Sprintf("%v: %v", hash, err))
+	}
+
+	sort.Strings(msgs)
+	return strings.Join(msgs, "; ")
+}
+
+// RemovalStepError is returned when a step of the removal strategy failed for all torrents
+type RemovalStepError struct {
+	Step string
+	Err  error
+}
+
+func (e *RemovalStepError) Error() string {
+	return fmt.Sprintf("%s torrents: %v", e.Step, e.Err)
+}
+
+func (e *RemovalStepError) Unwrap() error {
+	return e.Err
+}
+
+// remover is implemented by the clients to run the steps of the removal strategies, for multiple torrents at once
+type remover interface {
+	pause(ctx context.Context, hashes []string) error
+	resume(ctx context.Context, hashes []string) error
+	reannounce(ctx context.Context, hashes []string) error
+	remove(ctx context.Context, hashes []string, deleteData bool) error
+}
+
+// removeTorrents runs the steps of the removal strategy before removing the torrents
+func removeTorrents(ctx context.Context, r remover, hashes []string, deleteData bool,
+	strategy config.RemovalStrategy) error {
+	switch strategy.Strategy {
+	case config.RemovalStrategyNone:
+		break
+	case config.RemovalStrategyReannounce:
+		if err := reannounceTorrents(ctx, r, hashes, strategy); err != nil {
+			return err
+		}
+	case config.RemovalStrategyStopAnnounce:
+		// pause torrent
+		if err := r.pause(ctx, hashes); err != nil {
+			return &RemovalStepError{Step: "pause", Err: err}
+		}
+
+		if err := sleepContext(ctx, strategy.PauseDelay); err != nil {
+			return err
+		}
+
+		// resume torrent
+		if err := r.resume(ctx, hashes); err != nil {
+			return &RemovalStepError{Step: "resume", Err: err}
+		}
+
+		// sleep before re-announcing torrent
+		if err := sleepContext(ctx, strategy.ResumeDelay); err != nil {
+			return err
+		}
+
+		if err := reannounceTorrents(ctx, r, hashes, strategy); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("removal strategy not implemented: %q", strategy.Strategy)
+	}
+
+	// remove
+	if err := r.remove(ctx, hashes, deleteData); err != nil {
+		var torrentErrs TorrentErrors
+		if errors.As(err, &torrentErrs) {
+			return err
+		}
+		return &RemovalStepError{Step: "remove", Err: err}
+	}
+
+	return nil
+}
+
+func reannounceTorrents(ctx context.Context, r remover, hashes []string, strategy config.RemovalStrategy) error {
+	if err := r.reannounce(ctx, hashes); err != nil {
+		return &RemovalStepError{Step: "re-announce", Err: err}
+	}
+
+	// sleep before removing torrent
+	return sleepContext(ctx, strategy.ReannounceDelay)
+}
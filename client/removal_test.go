@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/l3uddz/tqm/config"
+)
+
+type fakeRemover struct {
+	steps []string
+	// step failing with the error
+	failStep string
+	failErr  error
+}
+
+func (r *fakeRemover) step(name string) error {
+	r.steps = append(r.steps, name)
+	if name == r.failStep {
+		return r.failErr
+	}
+	return nil
+}
+
+func (r *fakeRemover) pause(context.Context, []string) error { return r.step("pause") }
+
+func (r *fakeRemover) resume(context.Context, []string) error { return r.step("resume") }
+
+func (r *fakeRemover) reannounce(context.Context, []string) error { return r.step("re-announce") }
+
+func (r *fakeRemover) remove(context.Context, []string, bool) error { return r.step("remove") }
+
+func TestRemoveTorrents(t *testing.T) {
+	failed := errors.New("failed")
+
+	tests := []struct {
+		name      string
+		strategy  string
+		failStep  string
+		failErr   error
+		wantSteps []string
+		wantStep  string
+	}{
+		{name: "none", strategy: config.RemovalStrategyNone, wantSteps: []string{"remove"}},
+		{name: "reannounce", strategy: config.RemovalStrategyReannounce,
+			wantSteps: []string{"re-announce", "remove"}},
+		{name: "stop announce", strategy: config.RemovalStrategyStopAnnounce,
+			wantSteps: []string{"pause", "resume", "re-announce", "remove"}},
+		{name: "pause failed", strategy: config.RemovalStrategyStopAnnounce, failStep: "pause", failErr: failed,
+			wantSteps: []string{"pause"}, wantStep: "pause"},
+		{name: "resume failed", strategy: config.RemovalStrategyStopAnnounce, failStep: "resume", failErr: failed,
+			wantSteps: []string{"pause", "resume"}, wantStep: "resume"},
+		{name: "remove failed", strategy: config.RemovalStrategyNone, failStep: "remove", failErr: failed,
+			wantSteps: []string{"remove"}, wantStep: "remove"},
+		{name: "remove failed for some torrents", strategy: config.RemovalStrategyNone, failStep: "remove",
+			failErr: TorrentErrors{"abc": failed}, wantSteps: []string{"remove"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &fakeRemover{failStep: tt.failStep, failErr: tt.failErr}
+			strategy := config.RemovalStrategy{Strategy: tt.strategy, PauseDelay: time.Nanosecond,
+				ResumeDelay: time.Nanosecond, ReannounceDelay: time.Nanosecond}
+
+			err := removeTorrents(context.Background(), r, []string{"abc", "def"}, true, strategy)
+			if !reflect.DeepEqual(r.steps, tt.wantSteps) {
+				t.Errorf("steps = %v, want %v", r.steps, tt.wantSteps)
+			}
+
+			var stepErr *RemovalStepError
+			switch {
+			case tt.wantStep != "":
+				if !errors.As(err, &stepErr) || stepErr.Step != tt.wantStep {
+					t.Errorf("error = %v, want %s step error", err, tt.wantStep)
+				}
+			case tt.failErr != nil:
+				var torrentErrs TorrentErrors
+				if !errors.As(err, &torrentErrs) || errors.As(err, &stepErr) {
+					t.Errorf("error = %v, want torrent errors", err)
+				}
+			case err != nil:
+				t.Errorf("error = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestRemoveTorrentsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	r := &fakeRemover{}
+	strategy := config.RemovalStrategy{Strategy: config.RemovalStrategyStopAnnounce, PauseDelay: time.Hour}
+	if err := removeTorrents(ctx, r, []string{"abc"}, true, strategy); !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want %v", err, context.Canceled)
+	}
+	if want := []string{"pause"}; !reflect.DeepEqual(r.steps, want) {
+		t.Errorf("steps = %v, want %v", r.steps, want)
+	}
+}
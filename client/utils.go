@@ -1,25 +1,29 @@
 package client
 
 import (
+	"context"
 	"net"
+	"net/http"
 	"net/url"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/bobesa/go-domain-util/domainutil"
 	"github.com/sirupsen/logrus"
 )
 
-func parseTrackerDomain(trackerHost string) string {
+func parseTrackerHost(trackerUrl string) string {
 	// return empty host
-	if trackerHost == "" {
-		return trackerHost
+	if trackerUrl == "" {
+		return trackerUrl
 	}
 
 	// parse url components
-	u, err := url.Parse(trackerHost)
+	u, err := url.Parse(trackerUrl)
 	if err != nil {
-		logrus.WithError(err).Warnf("Failed parsing tracker host: %q", trackerHost)
-		return trackerHost
+		logrus.WithError(err).Warnf("Failed parsing tracker host: %q", trackerUrl)
+		return trackerUrl
 	}
 
 	// parse host
@@ -31,6 +35,15 @@ func parseTrackerDomain(trackerHost string) string {
 		}
 	}
 
+	return host
+}
+
+func parseTrackerDomain(trackerHost string) string {
+	host := parseTrackerHost(trackerHost)
+	if host == "" {
+		return host
+	}
+
 	// remove subdomain
 	if domain := domainutil.Domain(host); domain != "" {
 		return domain
@@ -38,3 +51,69 @@ func parseTrackerDomain(trackerHost string) string {
 
 	return host
 }
+
+// contextTransport applies the context of the running operation to the requests of api clients not accepting one
+type contextTransport struct {
+	base http.RoundTripper
+	ctx  context.Context
+	mtx  sync.Mutex
+}
+
+func (t *contextTransport) do(ctx context.Context, fn func() error) error {
+	t.mtx.Lock()
+	t.ctx = ctx
+	t.mtx.Unlock()
+
+	defer func() {
+		t.mtx.Lock()
+		t.ctx = nil
+		t.mtx.Unlock()
+	}()
+
+	return fn()
+}
+
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mtx.Lock()
+	ctx := t.ctx
+	t.mtx.Unlock()
+
+	if ctx != nil {
+		req = req.WithContext(ctx)
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// runContext runs the blocking call, returning early once the context is done (the call is abandoned and bound by
+// the read/write timeout of the client)
+func runContext(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sleepContext pauses for the duration, returning early once the context is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
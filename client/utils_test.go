@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRunContext(t *testing.T) {
+	errFailed := errors.New("failed")
+	block := make(chan struct{})
+	t.Cleanup(func() {
+		close(block)
+	})
+
+	tests := []struct {
+		name    string
+		cancel  bool
+		timeout time.Duration
+		fn      func() error
+		wantErr error
+	}{
+		{
+			name: "returns result",
+			fn:   func() error { return nil },
+		},
+		{
+			name:    "returns error",
+			fn:      func() error { return errFailed },
+			wantErr: errFailed,
+		},
+		{
+			name:   "cancelled before the call",
+			cancel: true,
+			fn: func() error {
+				t.Error("runContext() called fn with a cancelled context")
+				return nil
+			},
+			wantErr: context.Canceled,
+		},
+		{
+			name:    "abandons blocked call",
+			timeout: 20 * time.Millisecond,
+			fn: func() error {
+				<-block
+				return nil
+			},
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			if tt.timeout > 0 {
+				ctx, cancel = context.WithTimeout(context.Background(), tt.timeout)
+			}
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+
+			if err := runContext(ctx, tt.fn); !errors.Is(err, tt.wantErr) {
+				t.Errorf("runContext() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSleepContext(t *testing.T) {
+	if err := sleepContext(context.Background(), time.Millisecond); err != nil {
+		t.Errorf("sleepContext() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	if err := sleepContext(ctx, time.Minute); !errors.Is(err, context.Canceled) {
+		t.Errorf("sleepContext() cancelled error = %v, want %v", err, context.Canceled)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("sleepContext() cancelled returned after %s", elapsed)
+	}
+}
+
+func TestContextTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	tr := &contextTransport{base: http.DefaultTransport}
+	c := &http.Client{Transport: tr}
+
+	// requests are bound by the context of the operation
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := tr.do(ctx, func() error {
+		resp, err := c.Get(srv.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("do() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// the context is cleared after the operation
+	if tr.ctx != nil {
+		t.Errorf("do() kept the context of the operation")
+	}
+}
@@ -2,6 +2,9 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
@@ -10,6 +13,8 @@ import (
 	"github.com/l3uddz/tqm/config"
 	"github.com/l3uddz/tqm/expression"
 	"github.com/l3uddz/tqm/logger"
+	"github.com/l3uddz/tqm/notification"
+	"github.com/l3uddz/tqm/state"
 	"github.com/l3uddz/tqm/torrentfilemap"
 	"github.com/l3uddz/tqm/tracker"
 )
@@ -32,77 +37,75 @@ var cleanCmd = &cobra.Command{
 
 		// retrieve client object
 		clientName := args[0]
-		clientConfig, ok := config.Config.Clients[clientName]
-		if !ok {
-			log.Fatalf("No client configuration found for: %q", clientName)
+		summary := newRunSummary(log, "clean", clientName)
+		clientConfig, err := config.GetClientConfiguration(clientName)
+		if err != nil {
+			summary.fatal(log, exitCodeError, err, "Failed loading client configuration: %q", clientName)
 		}
+		warnUnknownClientSettings(log, clientName)
 
 		// validate client is enabled
-		if err := validateClientEnabled(clientConfig); err != nil {
-			log.WithError(err).Fatal("Failed validating client is enabled")
-		}
-
-		// retrieve client type
-		clientType, err := getClientConfigString("type", clientConfig)
-		if err != nil {
-			log.WithError(err).Fatal("Failed determining client type")
+		if !clientConfig.Enabled {
+			summary.fatal(log, exitCodeError, nil, "Failed validating client is enabled")
 		}
 
-		// retrieve client free space path
-		clientFreeSpacePath, _ := getClientConfigString("free_space_path", clientConfig)
+		// lock client
+		l := lockClient(log, summary)
+		defer l.Release()
 
 		// retrieve client filters
-		clientFilter, err := getClientFilter(clientConfig)
+		clientFilter, err := config.MergeFilters(clientConfig.Filter...)
 		if err != nil {
-			log.WithError(err).Fatal("Failed retrieving client filter")
+			summary.fatal(log, exitCodeError, err, "Failed retrieving client filter")
 		}
 
 		if flagFilterName != "" {
 			clientFilter, err = getFilter(flagFilterName)
 			if err != nil {
-				log.WithError(err).Fatal("Failed retrieving specified filter")
+				summary.fatal(log, exitCodeError, err, "Failed retrieving specified filter")
 			}
 		}
 
 		// compile client filters
 		exp, err := expression.Compile(clientFilter)
 		if err != nil {
-			log.WithError(err).Fatal("Failed compiling client filters")
+			summary.fatal(log, exitCodeError, err, "Failed compiling client filters")
 		}
 
 		// load client object
-		c, err := client.NewClient(*clientType, clientName, exp)
+		c, err := client.NewClient(clientConfig.Type, clientName, exp)
 		if err != nil {
-			log.WithError(err).Fatalf("Failed initializing client: %q", clientName)
+			summary.fatal(log, exitCodeError, err, "Failed initializing client: %q", clientName)
 		}
 
 		log.Infof("Initialized client %q, type: %s (%d trackers)", clientName, c.Type(), tracker.Loaded())
 
 		// connect to client
-		if err := c.Connect(); err != nil {
-			log.WithError(err).Fatal("Failed connecting")
+		if err := c.Connect(ctx); err != nil {
+			summary.fatal(log, exitCodeConnect, err, "Failed connecting")
 		} else {
 			log.Debugf("Connected to client")
 		}
 
 		// get free disk space (can/will be used by filters)
-		if clientFreeSpacePath != nil {
-			space, err := c.GetCurrentFreeSpace(*clientFreeSpacePath)
+		if clientConfig.FreeSpacePath != "" {
+			space, err := c.GetCurrentFreeSpace(ctx, clientConfig.FreeSpacePath)
 			if err != nil {
-				log.WithError(err).Warnf("Failed retrieving free-space for: %q", *clientFreeSpacePath)
+				log.WithError(err).Warnf("Failed retrieving free-space for: %q", clientConfig.FreeSpacePath)
 			} else {
-				log.Infof("Retrieved free-space for %q: %v (%.2f GB)", *clientFreeSpacePath,
+				log.Infof("Retrieved free-space for %q: %v (%.2f GB)", clientConfig.FreeSpacePath,
 					humanize.IBytes(uint64(space)), c.GetFreeSpace())
 			}
 		}
 
 		// retrieve torrents
-		torrents, err := c.GetTorrents()
+		torrents, err := c.GetTorrents(ctx)
 		if err != nil {
-			log.WithError(err).Fatal("Failed retrieving torrents")
+			summary.fatal(log, exitCodeConnect, err, "Failed retrieving torrents")
 		} else {
 			log.Infof("Retrieved %d torrents", len(torrents))
 		}
+		summary.Torrents = len(torrents)
 
 		if flagLogLevel > 1 {
 			if b, err := json.Marshal(torrents); err != nil {
@@ -116,10 +119,83 @@ var cleanCmd = &cobra.Command{
 		tfm := torrentfilemap.New(torrents)
 		log.Infof("Mapped torrents to %d unique torrent files", tfm.Length())
 
+		// prefetch tracker data used by the filters, lookups not prefetched are made with the same context
+		config.SetLookupContext(ctx, torrents)
+		prefetchTrackerData(ctx, log, torrents, exp)
+
+		// suspend removals for trackers with an outage
+		clientState := state.Client(clientName)
+		safeguards := clientConfig.Safeguards.Stricter(clientFilter.Safeguards)
+		suspendedTrackers, trackerErrors := detectTrackerOutages(torrents, usesUnregistered(exp),
+			clientState.TrackerErrors, safeguards)
+		if flagIgnoreSafeguards && len(suspendedTrackers) > 0 {
+			for tr, reason := range suspendedTrackers {
+				log.Warnf("Ignoring safeguards, not suspending removals for tracker %s: %s", tr, reason)
+			}
+
+			// without the previous run no tracker is suspended, and the current errors are persisted
+			suspendedTrackers, trackerErrors = detectTrackerOutages(torrents, usesUnregistered(exp), nil, safeguards)
+		}
+		for tr, reason := range suspendedTrackers {
+			log.Warnf("Suspended removals for tracker %s: %s", tr, reason)
+			notification.Send("tracker_suspended", fmt.Sprintf("Suspended removals for tracker %s on client %s: %s",
+				tr, clientName, reason), map[string]string{
+				"client":  clientName,
+				"tracker": tr,
+				"reason":  reason,
+			})
+		}
+
+		// check unregistered torrents did not spike since the previous run
+		unregistered := -1
+
+		if usesUnregistered(exp) {
+			unregistered = countUnregisteredTorrents(torrents, suspendedTrackers)
+			if err := checkUnregisteredSpike(clientState, unregistered, safeguards); err != nil {
+				if !flagIgnoreSafeguards {
+					summary.fatal(log, exitCodeSafeguards, err, "Aborted removing eligible torrents...")
+				}
+				log.WithError(err).Warn("Ignoring safeguards")
+			}
+		}
+
+		// torrents are only unregistered once their grace has passed
+		if unregistered >= 0 {
+			config.ApplyUnregisteredGrace(torrents, clientState, time.Now())
+		}
+
 		// remove torrents that are not ignored and match remove criteria
-		if err := removeEligibleTorrents(log, c, torrents, tfm); err != nil {
-			log.WithError(err).Fatal("Failed removing eligible torrents...")
+		result, err := removeEligibleTorrents(ctx, log, c, torrents, tfm, safeguards, suspendedTrackers, clientConfig.Retry,
+			clientConfig.Removal)
+		var opErrs operationErrors
+		switch {
+		case errors.As(err, &opErrs):
+			log.WithError(err).Errorf("Encountered %d failures while removing eligible torrents", len(opErrs))
+			summary.Error = err.Error()
+		case errors.Is(err, errSafeguardsExceeded):
+			summary.fatal(log, exitCodeSafeguards, err, "Aborted removing eligible torrents...")
+		case err != nil:
+			summary.fatal(log, exitCodeError, err, "Failed removing eligible torrents...")
 		}
+
+		// persist state for the next run
+		if (unregistered >= 0 || trackerErrors != nil) && !flagDryRun {
+			if unregistered >= 0 {
+				clientState.LastRun = time.Now()
+				clientState.Unregistered = unregistered
+			}
+			clientState.TrackerErrors = trackerErrors
+			if err := state.Save(); err != nil {
+				log.WithError(err).Error("Failed saving state")
+			}
+		}
+
+		// persist tracker cache
+		if err := tracker.SaveCache(); err != nil {
+			log.WithError(err).Error("Failed saving tracker cache")
+		}
+
+		summary.finish(result)
 	},
 }
 
@@ -127,4 +203,5 @@ func init() {
 	rootCmd.AddCommand(cleanCmd)
 
 	cleanCmd.Flags().StringVar(&flagFilterName, "filter", "", "Filter to use instead of client")
+	cleanCmd.Flags().BoolVar(&flagIgnoreSafeguards, "ignore-safeguards", false, "Remove torrents even when safeguards are exceeded")
 }
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/l3uddz/tqm/config"
+	"github.com/l3uddz/tqm/expression"
+	"github.com/l3uddz/tqm/logger"
+	"github.com/l3uddz/tqm/tracker"
+)
+
+var (
+	flagConfigCheckStrict bool
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration",
+	Long:  `This command can be used to inspect the configuration.`,
+}
+
+var configCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Validate the configuration",
+	Long: `This command can be used to validate the clients and filters of the configuration.
+
+Errors and warnings are logged, the command exits with a non-zero exit code when any errors were found.`,
+
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// init core
+		if !initialized {
+			initCore(false)
+			initialized = true
+		}
+
+		// set log
+		log := logger.GetLogger("config")
+
+		errs, warnings := 0, 0
+		reportError := func(format string, args ...interface{}) {
+			log.Errorf(format, args...)
+			errs++
+		}
+		reportWarning := func(format string, args ...interface{}) {
+			log.Warnf(format, args...)
+			warnings++
+		}
+
+		// clients
+		usedFilters := make(map[string]bool)
+		for _, name := range sortedKeys(config.Config.Clients) {
+			if clientConfig, err := config.GetClientConfiguration(name); err != nil {
+				reportError("Client %q: %v", name, err)
+			} else if err := clientConfig.CheckDownloadPath(); err != nil {
+				reportError("Client %q: %v", name, err)
+			}
+
+			if unknown, err := config.UnknownClientSettings(name); err == nil {
+				for _, setting := range unknown {
+					reportError("Client %q: %s: unknown setting", name, setting)
+				}
+			}
+
+			for _, f := range config.Config.Clients[name].Filter {
+				if _, ok := config.Config.Filters[f]; !ok {
+					reportError("Client %q: filter %q does not exist", name, f)
+				}
+				usedFilters[f] = true
+			}
+		}
+
+		// filters
+		for _, name := range sortedKeys(config.Config.Filters) {
+			for _, base := range config.Config.Filters[name].Extends {
+				usedFilters[base] = true
+			}
+		}
+
+		for _, name := range sortedKeys(config.Config.Filters) {
+			if !usedFilters[name] {
+				reportWarning("Filter %q: not used by any client or filter", name)
+			}
+
+			filter, err := config.GetFilter(name)
+			if err != nil {
+				reportError("Filter %q: %v", name, err)
+				continue
+			}
+
+			exp, err := expression.Compile(filter)
+			if err != nil {
+				reportError("Filter %q: %v", name, err)
+				continue
+			}
+
+			lints, err := expression.Lint(filter)
+			if err != nil {
+				reportError("Filter %q: %v", name, err)
+				continue
+			}
+
+			for _, l := range lints {
+				reportWarning("Filter %q: %s", name, l)
+			}
+
+			// tracker apis are only queried by the tracker functions
+			if !exp.UsesFunction("IsUnregistered", "TrackerSeeders", "TrackerSnatched", "IsFreeleech",
+				"TrackerMinSeedHours", "MeetsTrackerPolicy") {
+				continue
+			}
+
+			references := expression.TrackerReferences(filter)
+			sort.Strings(references)
+			for _, t := range references {
+				if builtin := tracker.BuiltinName(t); builtin != "" && tracker.Get(t, t) == nil {
+					reportWarning("Filter %q: tracker %q is referenced, but no %s credentials are configured",
+						name, t, builtin)
+				}
+			}
+		}
+
+		log.Infof("Checked %d clients and %d filters: %d errors, %d warnings", len(config.Config.Clients),
+			len(config.Config.Filters), errs, warnings)
+		if errs > 0 || (flagConfigCheckStrict && warnings > 0) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configCheckCmd)
+
+	configCheckCmd.Flags().BoolVar(&flagConfigCheckStrict, "strict", false, "Exit with a non-zero exit code on warnings")
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/l3uddz/tqm/config"
+	"github.com/l3uddz/tqm/logger"
+)
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print a JSON Schema of the configuration file",
+	Long:  `This command can be used to print a JSON Schema of the configuration file, which editors can use to validate it.`,
+
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// set log
+		log := logger.GetLogger("config")
+
+		b, err := json.MarshalIndent(config.Schema(), "", "  ")
+		if err != nil {
+			log.WithError(err).Fatal("Failed marshalling schema")
+		}
+
+		fmt.Println(string(b))
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configSchemaCmd)
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/l3uddz/tqm/config"
+	"github.com/l3uddz/tqm/expression"
+	"github.com/l3uddz/tqm/logger"
+)
+
+var filterCmd = &cobra.Command{
+	Use:   "filter",
+	Short: "Inspect configured filters",
+	Long:  `This command can be used to inspect the configured filters.`,
+}
+
+var filterShowCmd = &cobra.Command{
+	Use:   "show [FILTER]",
+	Short: "Show the resolved filter",
+	Long:  `This command can be used to show a filter with the filters it extends merged in.`,
+
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// init core
+		if !initialized {
+			initCore(false)
+			initialized = true
+		}
+
+		// set log
+		log := logger.GetLogger("filter")
+
+		// resolve filter
+		filter, err := config.GetFilter(args[0])
+		if err != nil {
+			log.WithError(err).Fatal("Failed resolving filter")
+		}
+
+		// validate filter compiles
+		if _, err := expression.Compile(filter); err != nil {
+			log.WithError(err).Fatal("Failed compiling filter")
+		}
+
+		b, err := yaml.Marshal(filter)
+		if err != nil {
+			log.WithError(err).Fatal("Failed marshalling filter")
+		}
+
+		fmt.Print(string(b))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(filterCmd)
+	filterCmd.AddCommand(filterShowCmd)
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/l3uddz/tqm/config"
+	"github.com/l3uddz/tqm/expression"
+	"github.com/l3uddz/tqm/logger"
+	"github.com/l3uddz/tqm/tracker"
+)
+
+type filterTestCase struct {
+	Name        string                 `yaml:"name"`
+	Torrent     map[string]interface{} `yaml:"torrent"`
+	FreeSpaceGB *float64               `yaml:"free_space_gb"`
+	Expect      string                 `yaml:"expect"`
+}
+
+var filterTestCmd = &cobra.Command{
+	Use:   "test [FILTER] [CASES_FILE]",
+	Short: "Test a filter against torrent fixtures",
+	Long: `This command can be used to test a filter against torrent fixtures with their expected decision.
+
+Each case has a partial torrent and the expected decision: ignore, remove, label:NAME or none.`,
+
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		// init core
+		if !initialized {
+			initCore(false)
+			initialized = true
+		}
+
+		// set log
+		log := logger.GetLogger("filter")
+
+		// tracker apis are never queried by tests
+		if err := tracker.Init(tracker.Config{
+			Aliases:  config.Config.Trackers.Aliases,
+			Policies: config.Config.Trackers.Policies,
+		}); err != nil {
+			log.WithError(err).Fatal("Failed initializing trackers")
+		}
+
+		// resolve and compile filter
+		filter, err := config.GetFilter(args[0])
+		if err != nil {
+			log.WithError(err).Fatal("Failed resolving filter")
+		}
+
+		exp, err := expression.Compile(filter)
+		if err != nil {
+			log.WithError(err).Fatal("Failed compiling filter")
+		}
+
+		// load test cases
+		cases, err := loadFilterTestCases(args[1])
+		if err != nil {
+			log.WithError(err).Fatal("Failed loading test cases")
+		}
+
+		// run test cases
+		failures := 0
+		for i, c := range cases {
+			name := c.Name
+			if name == "" {
+				name = fmt.Sprintf("case %d", i+1)
+			}
+
+			t, err := c.torrent()
+			if err != nil {
+				log.WithError(err).Errorf("FAIL %s: invalid torrent", name)
+				failures++
+				continue
+			}
+
+			decision, err := checkFilterTestCase(exp, t, c.Expect)
+			switch {
+			case err != nil:
+				log.WithError(err).Errorf("FAIL %s", name)
+				failures++
+			case decision.String() != c.Expect:
+				log.Errorf("FAIL %s: expected %s, got %s%s", name, c.Expect, decision, matchedRule(decision))
+				failures++
+			default:
+				log.Infof("PASS %s: %s%s", name, decision, matchedRule(decision))
+			}
+		}
+
+		log.Infof("Tested %d cases: %d passed, %d failed", len(cases), len(cases)-failures, failures)
+		if failures > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	filterCmd.AddCommand(filterTestCmd)
+}
+
+func loadFilterTestCases(path string) ([]filterTestCase, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read: %w", err)
+	}
+
+	var f struct {
+		Cases []filterTestCase `yaml:"cases"`
+	}
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+
+	for i, c := range f.Cases {
+		if !isFilterTestDecision(c.Expect) {
+			return nil, fmt.Errorf("case %d: invalid expect: %q", i+1, c.Expect)
+		}
+	}
+
+	return f.Cases, nil
+}
+
+func isFilterTestDecision(decision string) bool {
+	switch {
+	case decision == "ignore", decision == "remove", decision == "none":
+		return true
+	case strings.HasPrefix(decision, "label:") && len(decision) > len("label:"):
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *filterTestCase) torrent() (*config.Torrent, error) {
+	// decode the partial torrent via its json representation
+	b, err := json.Marshal(c.Torrent)
+	if err != nil {
+		return nil, fmt.Errorf("encode torrent: %w", err)
+	}
+
+	t := new(config.Torrent)
+	if err := json.Unmarshal(b, t); err != nil {
+		return nil, fmt.Errorf("decode torrent: %w", err)
+	}
+
+	freeSpace := 0.0
+	if c.FreeSpaceGB != nil {
+		freeSpace = *c.FreeSpaceGB
+		t.FreeSpaceSet = true
+	}
+	t.FreeSpaceGB = func() float64 {
+		return freeSpace
+	}
+
+	return t, nil
+}
+
+// checkFilterTestCase returns the decision for the torrent, label decisions are only made for label expectations
+// as torrents are relabeled regardless of the ignore and remove expressions.
+func checkFilterTestCase(exp *expression.Expressions, t *config.Torrent, expect string) (*expression.Decision, error) {
+	if !strings.HasPrefix(expect, "label:") {
+		d, err := exp.CheckRemoval(t)
+		if err != nil || d.Action != expression.ActionNone {
+			return d, err
+		}
+	}
+
+	return exp.CheckLabel(t)
+}
+
+func matchedRule(d *expression.Decision) string {
+	if d.Rule == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(" (matched: %s)", d.Rule)
+}
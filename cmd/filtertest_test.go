@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/l3uddz/tqm/config"
+	"github.com/l3uddz/tqm/expression"
+)
+
+func TestLoadFilterTestCases(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    int
+		wantErr string
+	}{
+		{
+			name: "valid cases",
+			data: `cases:
+  - name: old
+    torrent: {Ratio: 2.5}
+    expect: remove
+  - torrent: {Label: tv}
+    expect: label:permaseed
+  - expect: none`,
+			want: 3,
+		},
+		{
+			name:    "invalid expect",
+			data:    "cases:\n  - expect: delete",
+			wantErr: `case 1: invalid expect: "delete"`,
+		},
+		{
+			name:    "label without name",
+			data:    "cases:\n  - expect: none\n  - expect: 'label:'",
+			wantErr: `case 2: invalid expect: "label:"`,
+		},
+		{
+			name:    "invalid yaml",
+			data:    "cases: [",
+			wantErr: "decode",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "cases.yml")
+			if err := os.WriteFile(path, []byte(tt.data), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := loadFilterTestCases(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("loadFilterTestCases() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadFilterTestCases() error = %v", err)
+			}
+			if len(got) != tt.want {
+				t.Errorf("loadFilterTestCases() = %d cases, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterTestCaseTorrent(t *testing.T) {
+	freeSpace := 12.5
+	c := filterTestCase{
+		Torrent:     map[string]interface{}{"Name": "Test", "Ratio": 1.5, "Seeds": 4},
+		FreeSpaceGB: &freeSpace,
+	}
+
+	got, err := c.torrent()
+	if err != nil {
+		t.Fatalf("torrent() error = %v", err)
+	}
+	if got.Name != "Test" || got.Ratio != 1.5 || got.Seeds != 4 {
+		t.Errorf("torrent() = %+v", got)
+	}
+	if !got.FreeSpaceSet || got.FreeSpaceGB() != freeSpace {
+		t.Errorf("torrent() free space = %v (set %v), want %v", got.FreeSpaceGB(), got.FreeSpaceSet, freeSpace)
+	}
+
+	// free space is unset without free_space_gb
+	c = filterTestCase{Torrent: map[string]interface{}{"Name": "Test"}}
+	if got, err = c.torrent(); err != nil {
+		t.Fatalf("torrent() error = %v", err)
+	} else if got.FreeSpaceSet || got.FreeSpaceGB() != 0 {
+		t.Errorf("torrent() free space = %v (set %v), want unset", got.FreeSpaceGB(), got.FreeSpaceSet)
+	}
+
+	// invalid field types are rejected
+	c = filterTestCase{Torrent: map[string]interface{}{"Seeds": "many"}}
+	if _, err := c.torrent(); err == nil {
+		t.Errorf("torrent() with invalid field type: expected error")
+	}
+}
+
+func TestCheckFilterTestCase(t *testing.T) {
+	filter := &config.FilterConfiguration{
+		Ignore: []string{"Label == \"keep\""},
+		Remove: []string{"Ratio > 2.0", "SeedingDays > 30"},
+		Label: []config.FilterLabelConfiguration{
+			{Name: "permaseed", Update: []string{"Seeds < 3", "Label != \"permaseed\""}},
+		},
+	}
+
+	exp, err := expression.Compile(filter)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		torrent  config.Torrent
+		expect   string
+		want     string
+		wantRule string
+	}{
+		{
+			name:     "ignored",
+			torrent:  config.Torrent{Label: "keep", Ratio: 3},
+			expect:   "remove",
+			want:     "ignore",
+			wantRule: "Label == \"keep\"",
+		},
+		{
+			name:     "first matching remove",
+			torrent:  config.Torrent{Ratio: 3, SeedingDays: 40},
+			expect:   "remove",
+			want:     "remove",
+			wantRule: "Ratio > 2.0",
+		},
+		{
+			name:     "label regardless of remove",
+			torrent:  config.Torrent{Ratio: 3, Seeds: 1},
+			expect:   "label:permaseed",
+			want:     "label:permaseed",
+			wantRule: "Seeds < 3 && Label != \"permaseed\"",
+		},
+		{
+			name:     "label when nothing else matches",
+			torrent:  config.Torrent{Seeds: 1},
+			expect:   "none",
+			want:     "label:permaseed",
+			wantRule: "Seeds < 3 && Label != \"permaseed\"",
+		},
+		{
+			name:    "label requires all updates",
+			torrent: config.Torrent{Seeds: 1, Label: "permaseed"},
+			expect:  "label:permaseed",
+			want:    "none",
+		},
+		{
+			name:    "no match",
+			torrent: config.Torrent{Seeds: 10},
+			expect:  "none",
+			want:    "none",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := checkFilterTestCase(exp, &tt.torrent, tt.expect)
+			if err != nil {
+				t.Fatalf("checkFilterTestCase() error = %v", err)
+			}
+			if got.String() != tt.want || got.Rule != tt.wantRule {
+				t.Errorf("checkFilterTestCase() = %+v, want %s (%s)", got, tt.want, tt.wantRule)
+			}
+		})
+	}
+}
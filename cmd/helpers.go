@@ -1,6 +1,11 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/dustin/go-humanize"
@@ -8,24 +13,110 @@ import (
 
 	"github.com/l3uddz/tqm/client"
 	"github.com/l3uddz/tqm/config"
+	"github.com/l3uddz/tqm/expression"
+	"github.com/l3uddz/tqm/lock"
+	"github.com/l3uddz/tqm/state"
 	"github.com/l3uddz/tqm/torrentfilemap"
+	"github.com/l3uddz/tqm/tracker"
 )
 
+var (
+	// wait between removals, giving the client time to process them
+	removalInterval = 1 * time.Second
+	// wait between relabels
+	relabelInterval = 5 * time.Second
+)
+
+// lockClient takes the lock of the client, so runs against the same client do not overlap
+func lockClient(log *logrus.Entry, s *runSummary) *lock.Lock {
+	clientName := s.Client
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, clientName)
+
+	if flagLockTimeout > 0 {
+		log.Debugf("Waiting up to %s for the lock of client %q", flagLockTimeout, clientName)
+	}
+
+	l, err := lock.Acquire(filepath.Join(flagConfigFolder, "locks", name+".lock"), flagLockTimeout)
+	switch {
+	case errors.Is(err, lock.ErrLocked):
+		s.fatal(log, exitCodeLocked, err, "Another run is in progress for client: %q", clientName)
+	case err != nil:
+		s.fatal(log, exitCodeError, err, "Failed locking client: %q", clientName)
+	}
+
+	return l
+}
+
+// operationErrors aggregates the errors of the operations that failed during a run
+type operationErrors []error
+
+func (e operationErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// retryOperation runs the operation until it succeeds or the attempts are exhausted, backing off between attempts
+// (retries stop once the context is cancelled)
+func retryOperation(ctx context.Context, log *logrus.Entry, retry config.RetryConfiguration, op func() error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = op(); err == nil {
+			return nil
+		}
+
+		if attempt >= retry.MaxAttempts() {
+			return err
+		}
+
+		delay := retry.Delay(attempt)
+		log.WithError(err).Warnf("Attempt %d of %d failed, retrying in %s...", attempt, retry.MaxAttempts(), delay)
+		if !sleepContext(ctx, delay) {
+			return err
+		}
+	}
+}
+
+// sleepContext pauses for the duration, returning false when the context was cancelled first
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
 // relabel torrent that meet required filters
-func relabelEligibleTorrents(log *logrus.Entry, c client.Interface, torrents map[string]config.Torrent,
-	tfm *torrentfilemap.TorrentFileMap) error {
-	// vars
-	ignoredTorrents := 0
-	nonUniqueTorrents := 0
-	relabeledTorrents := 0
-	errorRelabelTorrents := 0
+func relabelEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.Interface, torrents map[string]config.Torrent,
+	tfm *torrentfilemap.TorrentFileMap, retry config.RetryConfiguration) (*relabelResult, error) {
+	result := &relabelResult{Actions: make([]torrentAction, 0)}
+	var errs operationErrors
 
 	// iterate torrents
 	for h, t := range torrents {
+		if ctx.Err() != nil {
+			log.Warn("Interrupted, skipping remaining relabels...")
+			break
+		}
+
 		if !tfm.IsUnique(t) {
 			// torrent file is not unique, files are contained within another torrent
 			// so we cannot safely change the label in-case of auto move
-			nonUniqueTorrents++
+			result.NonUnique++
 			log.Warnf("Skipping non unique torrent: %+v", t)
 			continue
 		}
@@ -35,11 +126,14 @@ func relabelEligibleTorrents(log *logrus.Entry, c client.Interface, torrents map
 		if err != nil {
 			// error while determining whether to relabel torrent
 			log.WithError(err).Errorf("Failed determining whether to relabel: %+v", t)
+			errs = append(errs, fmt.Errorf("evaluate %q: %w", t.Name, err))
+			result.Failed++
+			result.Actions = append(result.Actions, newTorrentAction("evaluate", t, err))
 			continue
 		} else if !relabel {
 			// torrent did not meet the relabel filters
 			log.Tracef("Not relabeling %s: %s", h, t.Name)
-			ignoredTorrents++
+			result.Ignored++
 			continue
 		}
 
@@ -49,56 +143,81 @@ func relabelEligibleTorrents(log *logrus.Entry, c client.Interface, torrents map
 		log.Infof("Ratio: %.3f / Seed days: %.3f / Seeds: %d / Label: %s / Tracker: %s / "+
 			"Tracker Status: %q", t.Ratio, t.SeedingDays, t.Seeds, t.Label, t.TrackerName, t.TrackerStatus)
 
+		action := newTorrentAction("relabel", t, nil)
+		action.Label = label
+
 		if !flagDryRun {
-			if err := c.SetTorrentLabel(t.Hash, label); err != nil {
-				log.WithError(err).Fatalf("Failed relabeling torrent: %+v", t)
-				errorRelabelTorrents++
+			// the current torrent is not interrupted (requests are bound by the client timeouts)
+			if err := retryOperation(ctx, log, retry, func() error {
+				return c.SetTorrentLabel(context.Background(), t.Hash, label)
+			}); err != nil {
+				log.WithError(err).Errorf("Failed relabeling torrent: %+v", t)
+				errs = append(errs, fmt.Errorf("relabel %q: %w", t.Name, err))
+				action.Error = err.Error()
+				result.Actions = append(result.Actions, action)
+				result.Failed++
+
+				if flagFailFast {
+					log.Warn("Fail-fast enabled, skipping remaining relabels...")
+					break
+				}
 				continue
 			}
 
 			log.Info("Relabeled")
-			time.Sleep(5 * time.Second)
+			sleepContext(ctx, relabelInterval)
 		} else {
 			log.Warn("Dry-run enabled, skipping relabel...")
 		}
 
-		relabeledTorrents++
+		result.Actions = append(result.Actions, action)
+		result.Relabeled++
 	}
 
 	// show result
 	log.Info("-----")
-	log.Infof("Ignored torrents: %d", ignoredTorrents)
-	if nonUniqueTorrents > 0 {
-		log.Infof("Non-unique torrents: %d", nonUniqueTorrents)
+	log.Infof("Ignored torrents: %d", result.Ignored)
+	if result.NonUnique > 0 {
+		log.Infof("Non-unique torrents: %d", result.NonUnique)
 	}
-	log.Infof("Relabeled torrents: %d, %d failures", relabeledTorrents, errorRelabelTorrents)
-	return nil
+	log.Infof("Relabeled torrents: %d, %d failures", result.Relabeled, result.Failed)
+	showTrackerCacheStats(log)
+
+	if len(errs) > 0 {
+		return result, errs
+	}
+	return result, nil
 }
 
 // remove torrents that meet remove filters
-func removeEligibleTorrents(log *logrus.Entry, c client.Interface, torrents map[string]config.Torrent,
-	tfm *torrentfilemap.TorrentFileMap) error {
+func removeEligibleTorrents(ctx context.Context, log *logrus.Entry, c client.Interface, torrents map[string]config.Torrent,
+	tfm *torrentfilemap.TorrentFileMap, safeguards config.SafeguardsConfiguration,
+	suspendedTrackers map[string]string, retry config.RetryConfiguration,
+	removal config.RemovalConfiguration) (*removeResult, error) {
 	// vars
-	ignoredTorrents := 0
-	softRemoveTorrents := 0
-	hardRemoveTorrents := 0
-	errorRemoveTorrents := 0
-	var removedTorrentBytes int64 = 0
+	queuedTorrents := len(torrents)
+	result := &removeResult{Actions: make([]torrentAction, 0)}
+	var errs operationErrors
 
-	// iterate torrents
+	// determine torrents to remove (checked against the safeguards, the remove filters are evaluated again right
+	// before each torrent is removed)
+	removeTorrents := make(map[string]config.Torrent)
 	for h, t := range torrents {
 		// should we ignore this torrent?
 		ignore, err := c.ShouldIgnore(&t)
 		if err != nil {
 			// error while determining whether to ignore torrent
 			log.WithError(err).Errorf("Failed determining whether to ignore: %+v", t)
+			errs = append(errs, fmt.Errorf("evaluate %q: %w", t.Name, err))
+			result.Failed++
+			result.Actions = append(result.Actions, newTorrentAction("evaluate", t, err))
 			delete(torrents, h)
 			continue
 		} else if ignore {
 			// torrent met ignore filter
 			log.Tracef("Ignoring torrent %s: %s", h, t.Name)
 			delete(torrents, h)
-			ignoredTorrents++
+			result.Ignored++
 			continue
 		}
 
@@ -106,6 +225,9 @@ func removeEligibleTorrents(log *logrus.Entry, c client.Interface, torrents map[
 		remove, err := c.ShouldRemove(&t)
 		if err != nil {
 			log.WithError(err).Errorf("Failed determining whether to remove: %+v", t)
+			errs = append(errs, fmt.Errorf("evaluate %q: %w", t.Name, err))
+			result.Failed++
+			result.Actions = append(result.Actions, newTorrentAction("evaluate", t, err))
 			// dont do any further operations on this torrent, but keep in the torrent file map
 			delete(torrents, h)
 			continue
@@ -113,81 +235,426 @@ func removeEligibleTorrents(log *logrus.Entry, c client.Interface, torrents map[
 			// torrent did not meet the remove filters
 			log.Tracef("Not removing %s: %s", h, t.Name)
 			continue
+		} else if _, suspended := suspendedTrackers[t.TrackerName]; suspended {
+			// removals are suspended for the tracker of this torrent
+			log.Debugf("Not removing %s, removals suspended for tracker %s: %s", h, t.TrackerName, t.Name)
+			result.Suspended++
+			continue
 		}
 
-		// torrent meets the remove filters
-		// are the files unique and eligible for a hard deletion (remove data)
-		uniqueTorrent := tfm.IsUnique(t)
-		removeMode := "Soft"
+		removeTorrents[h] = t
+	}
 
-		if uniqueTorrent {
-			// this torrent does not contains files found within other torrents (remove its data)
-			removeMode = "Hard"
+	// abort before removing any torrents when the safeguards are exceeded
+	if !flagIgnoreSafeguards {
+		if err := checkRemovalSafeguards(removeTorrents, queuedTorrents, tfm, safeguards); err != nil {
+			return result, err
 		}
+	}
 
-		// remove the torrent
-		log.Info("-----")
-		if !t.FreeSpaceSet {
-			log.Infof("%s removing: %q - %s", removeMode, t.Name, humanize.IBytes(uint64(t.DownloadedBytes)))
-		} else {
-			// show current free-space as well
-			log.Infof("%s removing: %q - %s - %.2f GB", removeMode, t.Name,
-				humanize.IBytes(uint64(t.DownloadedBytes)), t.FreeSpaceGB())
-		}
+	// remove torrents in rounds, torrents sharing files with another torrent of the round are deferred to the next
+	// round, so they are only hard removed once the other torrent was removed
+	pending := make([]config.Torrent, 0, len(removeTorrents))
+	for _, t := range removeTorrents {
+		pending = append(pending, t)
+	}
 
-		log.Infof("Ratio: %.3f / Seed days: %.3f / Seeds: %d / Label: %s / Tracker: %s / "+
-			"Tracker Status: %q", t.Ratio, t.SeedingDays, t.Seeds, t.Label, t.TrackerName, t.TrackerStatus)
+	aborted := false
+	for len(pending) > 0 && !aborted {
+		var round []torrentRemoval
+		round, pending = planRemovalRound(pending, tfm, removal)
 
-		if !flagDryRun {
-			// do remove
-			removed, err := c.RemoveTorrent(t.Hash, uniqueTorrent)
-			if err != nil {
-				log.WithError(err).Fatalf("Failed removing torrent: %+v", t)
-				// dont remove from torrents file map, but prevent further operations on this torrent
-				delete(torrents, h)
-				errorRemoveTorrents++
-				continue
-			} else if !removed {
-				log.Error("Failed removing torrent...")
-				// dont remove from torrents file map, but prevent further operations on this torrent
-				delete(torrents, h)
-				errorRemoveTorrents++
-				continue
-			} else {
-				log.Info("Removed")
+		for _, batch := range batchRemovals(round, removal.Batch()) {
+			if ctx.Err() != nil {
+				log.Warn("Interrupted, skipping remaining removals...")
+				aborted = true
+				break
+			}
+
+			// re-evaluate the remove filters right before removing, as they may depend on the space freed so far
+			eligible := make([]torrentRemoval, 0, len(batch))
+			for _, r := range batch {
+				t := r.torrent
+				remove, err := c.ShouldRemove(&t)
+				if err != nil {
+					log.WithError(err).Errorf("Failed determining whether to remove: %+v", t)
+					errs = append(errs, fmt.Errorf("evaluate %q: %w", t.Name, err))
+					result.Failed++
+					result.Actions = append(result.Actions, newTorrentAction("evaluate", t, err))
+					// dont do any further operations on this torrent, but keep in the torrent file map
+					delete(torrents, t.Hash)
+					continue
+				} else if !remove {
+					log.Debugf("Not removing %s, no longer meets the remove filters: %s", t.Hash, t.Name)
+					continue
+				}
+
+				removeMode := "Soft"
+				if r.hard {
+					removeMode = "Hard"
+				}
+
+				log.Info("-----")
+				if !t.FreeSpaceSet {
+					log.Infof("%s removing: %q - %s", removeMode, t.Name, humanize.IBytes(uint64(t.DownloadedBytes)))
+				} else {
+					// show current free-space as well
+					log.Infof("%s removing: %q - %s - %.2f GB", removeMode, t.Name,
+						humanize.IBytes(uint64(t.DownloadedBytes)), t.FreeSpaceGB())
+				}
 
-				// increase free space (if its a hard remove)
-				if uniqueTorrent && t.FreeSpaceSet {
+				log.Infof("Ratio: %.3f / Seed days: %.3f / Seeds: %d / Label: %s / Tracker: %s / "+
+					"Tracker Status: %q", t.Ratio, t.SeedingDays, t.Seeds, t.Label, t.TrackerName, t.TrackerStatus)
+
+				// increase free space (if its a hard remove), so the filters of the next torrents see it
+				if r.hard && t.FreeSpaceSet && !flagDryRun {
 					log.Tracef("Increasing free space by: %s", humanize.IBytes(uint64(t.DownloadedBytes)))
 					c.AddFreeSpace(t.DownloadedBytes)
 					log.Tracef("New free space: %.2f GB", c.GetFreeSpace())
 				}
 
-				time.Sleep(1 * time.Second)
+				eligible = append(eligible, r)
 			}
-		} else {
-			log.Warn("Dry-run enabled, skipping remove...")
-		}
 
-		if uniqueTorrent {
-			// increased hard removed counters
-			removedTorrentBytes += t.DownloadedBytes
-			hardRemoveTorrents++
-		} else {
-			// increase soft remove counters
-			softRemoveTorrents++
-		}
+			batch = eligible
+			if len(batch) == 0 {
+				continue
+			}
+
+			failures := make(map[string]error)
+			if !flagDryRun {
+				// do remove (the current batch is not interrupted, so its torrents are not left paused by the removal
+				// strategy, the removal is bound by the client timeouts)
+				hashes := make([]string, 0, len(batch))
+				for _, r := range batch {
+					hashes = append(hashes, r.torrent.Hash)
+				}
+
+				if len(batch) > 1 {
+					log.Infof("Removing %d torrents (strategy: %s)", len(batch), batch[0].strategy.Strategy)
+				}
+
+				err := retryOperation(ctx, log, retry, func() error {
+					err := c.RemoveTorrents(context.Background(), hashes, batch[0].hard, batch[0].strategy)
+
+					var stepErr *client.RemovalStepError
+					if errors.As(err, &stepErr) {
+						log.WithError(stepErr.Err).Warnf("Failed %s step of the %s removal strategy for %d torrents",
+							stepErr.Step, batch[0].strategy.Strategy, len(hashes))
+					}
+
+					// only retry the torrents that failed to be removed
+					var torrentErrs client.TorrentErrors
+					if errors.As(err, &torrentErrs) {
+						retryHashes := make([]string, 0, len(torrentErrs))
+						for _, h := range hashes {
+							if _, failed := torrentErrs[h]; failed {
+								retryHashes = append(retryHashes, h)
+							}
+						}
+						hashes = retryHashes
+					}
+					return err
+				})
+
+				var torrentErrs client.TorrentErrors
+				if errors.As(err, &torrentErrs) {
+					failures = torrentErrs
+				} else if err != nil {
+					for _, h := range hashes {
+						failures[h] = err
+					}
+				}
+			} else {
+				log.Warn("Dry-run enabled, skipping remove...")
+			}
+
+			for _, r := range batch {
+				t := r.torrent
+				action := newTorrentAction("soft_remove", t, nil)
+				if r.hard {
+					action.Action = "hard_remove"
+				}
+
+				if err, failed := failures[t.Hash]; failed {
+					log.WithError(err).Errorf("Failed removing torrent: %+v", t)
+					if r.hard && t.FreeSpaceSet {
+						// the space was not freed
+						c.AddFreeSpace(-t.DownloadedBytes)
+					}
+					// dont remove from torrents file map, but prevent further operations on this torrent
+					delete(torrents, t.Hash)
+					errs = append(errs, fmt.Errorf("remove %q: %w", t.Name, err))
+					action.Error = err.Error()
+					result.Actions = append(result.Actions, action)
+					result.Failed++
+					continue
+				}
+
+				if !flagDryRun {
+					log.Infof("Removed: %q", t.Name)
+				}
+
+				result.Actions = append(result.Actions, action)
+				if r.hard {
+					// increased hard removed counters
+					result.ReclaimedBytes += t.DownloadedBytes
+					result.HardRemoved++
+				} else {
+					// increase soft remove counters
+					result.SoftRemoved++
+				}
+
+				// remove the torrent from the torrent file map
+				tfm.Remove(t)
+				delete(torrents, t.Hash)
+			}
+
+			if len(failures) > 0 && flagFailFast {
+				log.Warn("Fail-fast enabled, skipping remaining removals...")
+				aborted = true
+				break
+			}
 
-		// remove the torrent from the torrent file map
-		tfm.Remove(t)
-		delete(torrents, h)
+			if !flagDryRun {
+				sleepContext(ctx, removalInterval)
+			}
+		}
 	}
 
 	// show result
 	log.Info("-----")
-	log.Infof("Ignored torrents: %d", ignoredTorrents)
-	log.WithField("reclaimed_space", humanize.IBytes(uint64(removedTorrentBytes))).
+	log.Infof("Ignored torrents: %d", result.Ignored)
+	if result.Suspended > 0 {
+		log.Infof("Suspended torrents: %d (trackers: %d)", result.Suspended, len(suspendedTrackers))
+	}
+	log.WithField("reclaimed_space", humanize.IBytes(uint64(result.ReclaimedBytes))).
 		Infof("Removed torrents: %d hard, %d soft and %d failures",
-			hardRemoveTorrents, softRemoveTorrents, errorRemoveTorrents)
+			result.HardRemoved, result.SoftRemoved, result.Failed)
+	showTrackerCacheStats(log)
+
+	if len(errs) > 0 {
+		return result, errs
+	}
+	return result, nil
+}
+
+type torrentRemoval struct {
+	torrent  config.Torrent
+	hard     bool
+	strategy config.RemovalStrategy
+}
+
+// planRemovalRound returns the removals of the round and the torrents deferred to the next round, as whether they
+// are unique depends on the removal of another torrent of the round
+func planRemovalRound(pending []config.Torrent, tfm *torrentfilemap.TorrentFileMap,
+	removal config.RemovalConfiguration) ([]torrentRemoval, []config.Torrent) {
+	round := make([]torrentRemoval, 0, len(pending))
+	deferred := make([]config.Torrent, 0)
+
+	// simulate the removals of the round
+	simulated := tfm.Clone()
+	for _, t := range pending {
+		// are the files unique and eligible for a hard deletion (remove data)
+		unique := tfm.IsUnique(t)
+		if unique != simulated.IsUnique(t) {
+			deferred = append(deferred, t)
+			continue
+		}
+
+		round = append(round, torrentRemoval{
+			torrent:  t,
+			hard:     unique,
+			strategy: removal.ForTracker(t.TrackerName),
+		})
+		simulated.Remove(t)
+	}
+
+	return round, deferred
+}
+
+// batchRemovals groups the removals removed by the same call to the client, of up to size torrents
+func batchRemovals(removals []torrentRemoval, size int) [][]torrentRemoval {
+	type batchKey struct {
+		hard     bool
+		strategy config.RemovalStrategy
+	}
+
+	batches := make([][]torrentRemoval, 0)
+	open := make(map[batchKey]int)
+	for _, r := range removals {
+		key := batchKey{hard: r.hard, strategy: r.strategy}
+		if i, ok := open[key]; ok && len(batches[i]) < size {
+			batches[i] = append(batches[i], r)
+			continue
+		}
+
+		open[key] = len(batches)
+		batches = append(batches, []torrentRemoval{r})
+	}
+
+	return batches
+}
+
+// checkRemovalSafeguards returns an error when removing the torrents would exceed any of the safeguards
+func checkRemovalSafeguards(removeTorrents map[string]config.Torrent, queuedTorrents int,
+	tfm *torrentfilemap.TorrentFileMap, safeguards config.SafeguardsConfiguration) error {
+	exceeded := make([]string, 0)
+
+	if safeguards.MaxRemoved > 0 && len(removeTorrents) > safeguards.MaxRemoved {
+		exceeded = append(exceeded, fmt.Sprintf("%d torrents would be removed (max_removed: %d)",
+			len(removeTorrents), safeguards.MaxRemoved))
+	}
+
+	if safeguards.MaxRemovedPercent > 0 && queuedTorrents > 0 {
+		if percent := float64(len(removeTorrents)) / float64(queuedTorrents) * 100; percent > safeguards.MaxRemovedPercent {
+			exceeded = append(exceeded, fmt.Sprintf("%.1f%% of the queue would be removed (max_removed_percent: %v)",
+				percent, safeguards.MaxRemovedPercent))
+		}
+	}
+
+	if safeguards.MaxHardRemovedGB > 0 {
+		// simulate the removals to determine which would remove data
+		simulated := tfm.Clone()
+		var hardRemovedBytes int64 = 0
+		for _, t := range removeTorrents {
+			if simulated.IsUnique(t) {
+				hardRemovedBytes += t.DownloadedBytes
+			}
+			simulated.Remove(t)
+		}
+
+		if gb := float64(hardRemovedBytes) / humanize.GiByte; gb > safeguards.MaxHardRemovedGB {
+			exceeded = append(exceeded, fmt.Sprintf("%.2f GB would be hard removed (max_hard_removed_gb: %v)",
+				gb, safeguards.MaxHardRemovedGB))
+		}
+	}
+
+	if len(exceeded) > 0 {
+		return fmt.Errorf("%w: %s", errSafeguardsExceeded, strings.Join(exceeded, ", "))
+	}
+
+	return nil
+}
+
+// checkUnregisteredSpike returns an error when the unregistered torrents increased by more than the safeguard allows
+// since the previous run of the client
+func checkUnregisteredSpike(clientState *state.ClientState, unregistered int,
+	safeguards config.SafeguardsConfiguration) error {
+	if safeguards.MaxUnregisteredIncrease == 0 || clientState.LastRun.IsZero() {
+		return nil
+	}
+
+	if increase := unregistered - clientState.Unregistered; increase > safeguards.MaxUnregisteredIncrease {
+		return fmt.Errorf("%w: unregistered torrents increased from %d to %d since %s "+
+			"(max_unregistered_increase: %d)", errSafeguardsExceeded, clientState.Unregistered, unregistered,
+			clientState.LastRun.Format(time.RFC3339), safeguards.MaxUnregisteredIncrease)
+	}
+
 	return nil
 }
+
+// detectTrackerOutages returns the trackers, with the reason, whose removals are suspended for this run as their
+// unregistered or errored torrents increased since the previous run by more than the safeguard allows, along with the
+// errored torrents per tracker to persist for the next run (suspended trackers keep the count of the previous run)
+func detectTrackerOutages(torrents map[string]config.Torrent, unregistered bool, previous map[string]int,
+	safeguards config.SafeguardsConfiguration) (map[string]string, map[string]int) {
+	suspended := make(map[string]string)
+	if safeguards.MaxTrackerErrorPercent == 0 {
+		return suspended, nil
+	}
+
+	minTorrents := safeguards.MinTrackerTorrents
+	if minTorrents == 0 {
+		minTorrents = 10
+	}
+
+	// count torrents and errored torrents per tracker
+	total := make(map[string]int)
+	errored := make(map[string]int)
+	for _, t := range torrents {
+		total[t.TrackerName]++
+		if t.TrackerErrorCategory() != "" || (unregistered && t.IsUnregistered()) {
+			errored[t.TrackerName]++
+		}
+	}
+
+	counts := make(map[string]int, len(total))
+	for name, n := range total {
+		counts[name] = errored[name]
+
+		// trackers are only compared once their count of a previous run is known
+		prev, ok := previous[name]
+		if !ok || n < minTorrents {
+			continue
+		}
+
+		increase := errored[name] - prev
+		if percent := float64(increase) / float64(n) * 100; percent > safeguards.MaxTrackerErrorPercent {
+			suspended[name] = fmt.Sprintf("unregistered or errored torrents increased from %d to %d of %d torrents "+
+				"(%.1f%%) since the previous run (max_tracker_error_percent: %v)", prev, errored[name], n, percent,
+				safeguards.MaxTrackerErrorPercent)
+			counts[name] = prev
+		}
+	}
+
+	return suspended, counts
+}
+
+func countUnregisteredTorrents(torrents map[string]config.Torrent, suspendedTrackers map[string]string) int {
+	unregistered := 0
+	for _, t := range torrents {
+		if _, suspended := suspendedTrackers[t.TrackerName]; suspended {
+			continue
+		}
+
+		if t.IsUnregistered() {
+			unregistered++
+		}
+	}
+
+	return unregistered
+}
+
+// warnUnknownClientSettings logs the settings of the client configuration not known by its type, e.g. typos
+func warnUnknownClientSettings(log *logrus.Entry, clientName string) {
+	unknown, err := config.UnknownClientSettings(clientName)
+	if err != nil {
+		log.WithError(err).Warn("Failed checking for unknown client settings")
+		return
+	}
+
+	for _, setting := range unknown {
+		log.Warnf("Unknown setting of client %q ignored: %s", clientName, setting)
+	}
+}
+
+// usesUnregistered returns whether the filters depend on torrents being unregistered
+func usesUnregistered(exp *expression.Expressions) bool {
+	return exp.UsesFunction("IsUnregistered") || exp.UsesField("UnregisteredSince", "UnregisteredHours")
+}
+
+// prefetch tracker api lookups used by the filters
+func prefetchTrackerData(ctx context.Context, log *logrus.Entry, torrents map[string]config.Torrent, exp *expression.Expressions) {
+	if tracker.Loaded() == 0 {
+		return
+	}
+
+	unregistered := usesUnregistered(exp)
+	metadata := exp.UsesFunction("TrackerSeeders", "TrackerSnatched", "IsFreeleech", "TrackerMinSeedHours",
+		"MeetsTrackerPolicy")
+	if !unregistered && !metadata {
+		return
+	}
+
+	start := time.Now()
+	prefetched := config.PrefetchTrackerData(ctx, torrents, unregistered, metadata)
+	log.Infof("Prefetched tracker data for %d torrents in %s", prefetched,
+		time.Since(start).Round(time.Millisecond))
+}
+
+func showTrackerCacheStats(log *logrus.Entry) {
+	if hits, misses := tracker.CacheStats(); hits+misses > 0 {
+		log.Infof("Tracker cache: %d hits, %d misses", hits, misses)
+	}
+}
@@ -0,0 +1,720 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/sirupsen/logrus"
+
+	"github.com/l3uddz/tqm/client"
+	"github.com/l3uddz/tqm/config"
+	"github.com/l3uddz/tqm/state"
+	"github.com/l3uddz/tqm/torrentfilemap"
+)
+
+func init() {
+	removalInterval = 0
+	relabelInterval = 0
+}
+
+func testLog() *logrus.Entry {
+	l := logrus.New()
+	l.SetOutput(io.Discard)
+	return logrus.NewEntry(l)
+}
+
+type fakeClient struct {
+	client.Interface
+
+	freeSpaceGB float64
+	// removes torrents while the free space is below the threshold (when set)
+	minFreeSpaceGB float64
+	// attempts failing to remove the torrent (by hash)
+	failures map[string]int
+	// removals failing, regardless of the torrent
+	failRemovals int
+	// calls failing with a strategy step error
+	failSteps int
+	// interrupts the run with the first call
+	cancel context.CancelFunc
+	// relabels failing, regardless of the torrent
+	failRelabels int
+
+	calls [][]string
+}
+
+func (c *fakeClient) ShouldIgnore(*config.Torrent) (bool, error) { return false, nil }
+
+func (c *fakeClient) ShouldRemove(t *config.Torrent) (bool, error) {
+	if c.minFreeSpaceGB > 0 {
+		return t.FreeSpaceGB() < c.minFreeSpaceGB, nil
+	}
+	return true, nil
+}
+
+func (c *fakeClient) RemoveTorrents(ctx context.Context, hashes []string, _ bool, _ config.RemovalStrategy) error {
+	c.calls = append(c.calls, append([]string{}, hashes...))
+
+	if c.cancel != nil {
+		c.cancel()
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+	if c.failSteps > 0 {
+		c.failSteps--
+		return &client.RemovalStepError{Step: "pause", Err: errors.New("failed")}
+	}
+
+	errs := make(client.TorrentErrors)
+	for _, h := range hashes {
+		switch {
+		case c.failures[h] > 0:
+			c.failures[h]--
+			errs[h] = errors.New("failed")
+		case c.failRemovals > 0:
+			c.failRemovals--
+			errs[h] = errors.New("failed")
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (c *fakeClient) ShouldRelabel(t *config.Torrent) (string, bool, error) {
+	if t.Label == "error" {
+		return "", false, errors.New("failed")
+	}
+	return "relabeled", t.Label != "relabeled", nil
+}
+
+func (c *fakeClient) SetTorrentLabel(_ context.Context, hash string, _ string) error {
+	c.calls = append(c.calls, []string{hash})
+
+	if c.failRelabels > 0 {
+		c.failRelabels--
+		return errors.New("failed")
+	}
+	return nil
+}
+
+func (c *fakeClient) AddFreeSpace(bytes int64) { c.freeSpaceGB += float64(bytes) / humanize.GiByte }
+
+func (c *fakeClient) GetFreeSpace() float64 { return c.freeSpaceGB }
+
+func newTestTorrents(c *fakeClient, n int, size int64) map[string]config.Torrent {
+	torrents := make(map[string]config.Torrent)
+	for i := 0; i < n; i++ {
+		h := fmt.Sprintf("hash%d", i)
+		torrents[h] = config.Torrent{
+			Hash:            h,
+			Name:            h,
+			DownloadedBytes: size,
+			Files:           []string{"/data/" + h},
+			FreeSpaceGB:     c.GetFreeSpace,
+			FreeSpaceSet:    true,
+		}
+	}
+
+	return torrents
+}
+
+func TestRemoveEligibleTorrentsFreeSpace(t *testing.T) {
+	tests := []struct {
+		name          string
+		batchSize     int
+		failRemovals  int
+		wantRemoved   int
+		wantFailed    int
+		wantFreeSpace float64
+	}{
+		{name: "sequential", batchSize: 1, wantRemoved: 2, wantFreeSpace: 16},
+		{name: "batched", batchSize: 10, wantRemoved: 2, wantFreeSpace: 16},
+		{name: "failed removals do not free space", batchSize: 1, wantRemoved: 2, wantFailed: 1,
+			failRemovals: 1, wantFreeSpace: 16},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &fakeClient{freeSpaceGB: 10, minFreeSpaceGB: 15, failRemovals: tt.failRemovals}
+			torrents := newTestTorrents(c, 5, 3*humanize.GiByte)
+
+			removal := config.RemovalConfiguration{BatchSize: tt.batchSize}
+			result, _ := removeEligibleTorrents(context.Background(), testLog(), c, torrents,
+				torrentfilemap.New(torrents), config.SafeguardsConfiguration{}, nil,
+				config.RetryConfiguration{Attempts: 1}, removal)
+
+			if result.HardRemoved != tt.wantRemoved {
+				t.Errorf("hard removed = %d, want %d", result.HardRemoved, tt.wantRemoved)
+			}
+			if result.Failed != tt.wantFailed {
+				t.Errorf("failed = %d, want %d", result.Failed, tt.wantFailed)
+			}
+			if c.freeSpaceGB != tt.wantFreeSpace {
+				t.Errorf("free space = %.2f GB, want %.2f GB", c.freeSpaceGB, tt.wantFreeSpace)
+			}
+		})
+	}
+}
+
+func TestPlanRemovalRound(t *testing.T) {
+	torrents := map[string]config.Torrent{
+		"a": {Hash: "a", Files: []string{"/a"}},
+		// b and c share their files
+		"b": {Hash: "b", Files: []string{"/b"}},
+		"c": {Hash: "c", Files: []string{"/b"}},
+		// d shares its files with a torrent not being removed
+		"d": {Hash: "d", Files: []string{"/d"}},
+		"e": {Hash: "e", Files: []string{"/d"}},
+	}
+	tfm := torrentfilemap.New(torrents)
+
+	pending := []config.Torrent{torrents["a"], torrents["b"], torrents["c"], torrents["d"]}
+	removal := config.RemovalConfiguration{}
+
+	// the first round removes b soft, c is deferred as it becomes unique once b was removed
+	round, deferred := planRemovalRound(pending, tfm, removal)
+	got := make(map[string]bool)
+	for _, r := range round {
+		got[r.torrent.Hash] = r.hard
+	}
+	if want := map[string]bool{"a": true, "b": false, "d": false}; !reflect.DeepEqual(got, want) {
+		t.Errorf("round = %v, want %v", got, want)
+	}
+	if len(deferred) != 1 || deferred[0].Hash != "c" {
+		t.Fatalf("deferred = %v, want [c]", deferred)
+	}
+
+	// once b was removed, c is hard removed
+	for _, r := range round {
+		tfm.Remove(r.torrent)
+	}
+	round, deferred = planRemovalRound(deferred, tfm, removal)
+	if len(round) != 1 || round[0].torrent.Hash != "c" || !round[0].hard || len(deferred) != 0 {
+		t.Errorf("round = %+v, deferred = %v, want c hard removed", round, deferred)
+	}
+}
+
+func TestBatchRemovals(t *testing.T) {
+	none := config.RemovalStrategy{Strategy: config.RemovalStrategyNone}
+	reannounce := config.RemovalStrategy{Strategy: config.RemovalStrategyReannounce}
+
+	removal := func(hash string, hard bool, strategy config.RemovalStrategy) torrentRemoval {
+		return torrentRemoval{torrent: config.Torrent{Hash: hash}, hard: hard, strategy: strategy}
+	}
+
+	tests := []struct {
+		name     string
+		removals []torrentRemoval
+		size     int
+		want     [][]string
+	}{
+		{
+			name:     "single",
+			removals: []torrentRemoval{removal("a", true, none), removal("b", true, none)},
+			size:     1,
+			want:     [][]string{{"a"}, {"b"}},
+		},
+		{
+			name: "size",
+			removals: []torrentRemoval{removal("a", true, none), removal("b", true, none),
+				removal("c", true, none)},
+			size: 2,
+			want: [][]string{{"a", "b"}, {"c"}},
+		},
+		{
+			name: "hard and soft",
+			removals: []torrentRemoval{removal("a", true, none), removal("b", false, none),
+				removal("c", true, none)},
+			size: 10,
+			want: [][]string{{"a", "c"}, {"b"}},
+		},
+		{
+			name: "strategies",
+			removals: []torrentRemoval{removal("a", true, none), removal("b", true, reannounce),
+				removal("c", true, reannounce)},
+			size: 10,
+			want: [][]string{{"a"}, {"b", "c"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([][]string, 0)
+			for _, batch := range batchRemovals(tt.removals, tt.size) {
+				hashes := make([]string, 0, len(batch))
+				for _, r := range batch {
+					hashes = append(hashes, r.torrent.Hash)
+				}
+				got = append(got, hashes)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("batches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoveEligibleTorrentsRetry(t *testing.T) {
+	tests := []struct {
+		name        string
+		failures    map[string]int
+		failSteps   int
+		wantCalls   [][]string
+		wantRemoved int
+		wantFailed  int
+	}{
+		{name: "removed", wantCalls: [][]string{{"hash0", "hash1"}}, wantRemoved: 2},
+		{name: "only failed torrents are retried", failures: map[string]int{"hash1": 1},
+			wantCalls: [][]string{{"hash0", "hash1"}, {"hash1"}}, wantRemoved: 2},
+		{name: "failed torrents", failures: map[string]int{"hash1": 5},
+			wantCalls: [][]string{{"hash0", "hash1"}, {"hash1"}}, wantRemoved: 1, wantFailed: 1},
+		{name: "failed strategy step retries the batch", failSteps: 1,
+			wantCalls: [][]string{{"hash0", "hash1"}, {"hash0", "hash1"}}, wantRemoved: 2},
+		{name: "failed strategy steps", failSteps: 5,
+			wantCalls: [][]string{{"hash0", "hash1"}, {"hash0", "hash1"}}, wantFailed: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &fakeClient{failures: tt.failures, failSteps: tt.failSteps}
+			torrents := newTestTorrents(c, 2, humanize.GiByte)
+
+			removal := config.RemovalConfiguration{BatchSize: 10}
+			result, _ := removeEligibleTorrents(context.Background(), testLog(), c, torrents,
+				torrentfilemap.New(torrents), config.SafeguardsConfiguration{}, nil,
+				config.RetryConfiguration{Attempts: 2, Backoff: time.Nanosecond}, removal)
+
+			// the order of the torrents within a batch is not defined
+			for _, hashes := range c.calls {
+				sort.Strings(hashes)
+			}
+			if !reflect.DeepEqual(c.calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", c.calls, tt.wantCalls)
+			}
+			if result.HardRemoved != tt.wantRemoved {
+				t.Errorf("hard removed = %d, want %d", result.HardRemoved, tt.wantRemoved)
+			}
+			if result.Failed != tt.wantFailed {
+				t.Errorf("failed = %d, want %d", result.Failed, tt.wantFailed)
+			}
+		})
+	}
+}
+
+func TestRemoveEligibleTorrentsInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := &fakeClient{cancel: cancel}
+	torrents := newTestTorrents(c, 3, humanize.GiByte)
+
+	// the interrupt completes the removal in progress, without removing the remaining torrents
+	result, _ := removeEligibleTorrents(ctx, testLog(), c, torrents, torrentfilemap.New(torrents),
+		config.SafeguardsConfiguration{}, nil, config.RetryConfiguration{Attempts: 3}, config.RemovalConfiguration{})
+
+	if len(c.calls) != 1 {
+		t.Errorf("calls = %v, want 1", c.calls)
+	}
+	if result.HardRemoved != 1 || result.Failed != 0 {
+		t.Errorf("hard removed, failed = %d, %d, want 1, 0", result.HardRemoved, result.Failed)
+	}
+}
+
+func TestCheckRemovalSafeguards(t *testing.T) {
+	torrent := func(hash string, gb int64, files ...string) config.Torrent {
+		return config.Torrent{Hash: hash, DownloadedBytes: gb * humanize.GiByte, Files: files}
+	}
+
+	queued := map[string]config.Torrent{
+		"a":    torrent("a", 10, "/data/a"),
+		"b":    torrent("b", 10, "/data/b"),
+		"b2":   torrent("b2", 10, "/data/b"),
+		"c":    torrent("c", 10, "/data/c"),
+		"keep": torrent("keep", 10, "/data/c"),
+	}
+	tfm := torrentfilemap.New(queued)
+
+	removals := func(hashes ...string) map[string]config.Torrent {
+		m := make(map[string]config.Torrent)
+		for _, h := range hashes {
+			m[h] = queued[h]
+		}
+		return m
+	}
+
+	tests := []struct {
+		name       string
+		remove     map[string]config.Torrent
+		safeguards config.SafeguardsConfiguration
+		wantErr    string
+	}{
+		{
+			name:   "no safeguards",
+			remove: removals("a", "b", "b2", "c", "keep"),
+		},
+		{
+			name:       "within max removed",
+			remove:     removals("a", "b"),
+			safeguards: config.SafeguardsConfiguration{MaxRemoved: 2},
+		},
+		{
+			name:       "exceeds max removed",
+			remove:     removals("a", "b", "c"),
+			safeguards: config.SafeguardsConfiguration{MaxRemoved: 2},
+			wantErr:    "3 torrents would be removed (max_removed: 2)",
+		},
+		{
+			name:       "exceeds max removed percent",
+			remove:     removals("a", "b", "c"),
+			safeguards: config.SafeguardsConfiguration{MaxRemovedPercent: 50},
+			wantErr:    "60.0% of the queue would be removed (max_removed_percent: 50)",
+		},
+		{
+			name:       "cross-seeded torrents are hard removed once",
+			remove:     removals("b", "b2"),
+			safeguards: config.SafeguardsConfiguration{MaxHardRemovedGB: 10},
+		},
+		{
+			name:       "kept torrents prevent hard removal",
+			remove:     removals("a", "c"),
+			safeguards: config.SafeguardsConfiguration{MaxHardRemovedGB: 10},
+		},
+		{
+			name:       "exceeds max hard removed",
+			remove:     removals("a", "b", "b2"),
+			safeguards: config.SafeguardsConfiguration{MaxHardRemovedGB: 15},
+			wantErr:    "20.00 GB would be hard removed (max_hard_removed_gb: 15)",
+		},
+		{
+			name:       "reports every exceeded safeguard",
+			remove:     removals("a", "b", "c"),
+			safeguards: config.SafeguardsConfiguration{MaxRemoved: 1, MaxRemovedPercent: 50},
+			wantErr:    "3 torrents would be removed (max_removed: 1), 60.0% of the queue would be removed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkRemovalSafeguards(tt.remove, len(queued), tfm, tt.safeguards)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("checkRemovalSafeguards() error = %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, errSafeguardsExceeded) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("checkRemovalSafeguards() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// the torrent file map is left untouched
+	if !tfm.IsUnique(queued["a"]) || tfm.IsUnique(queued["b"]) {
+		t.Errorf("checkRemovalSafeguards() modified the torrent file map")
+	}
+}
+
+func TestCheckUnregisteredSpike(t *testing.T) {
+	lastRun := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		state        state.ClientState
+		unregistered int
+		maxIncrease  int
+		wantErr      bool
+	}{
+		{
+			name:         "no safeguard",
+			state:        state.ClientState{LastRun: lastRun},
+			unregistered: 100,
+		},
+		{
+			name:         "first run",
+			unregistered: 100,
+			maxIncrease:  5,
+		},
+		{
+			name:         "within increase",
+			state:        state.ClientState{LastRun: lastRun, Unregistered: 10},
+			unregistered: 15,
+			maxIncrease:  5,
+		},
+		{
+			name:         "decrease",
+			state:        state.ClientState{LastRun: lastRun, Unregistered: 10},
+			unregistered: 0,
+			maxIncrease:  5,
+		},
+		{
+			name:         "exceeds increase",
+			state:        state.ClientState{LastRun: lastRun, Unregistered: 10},
+			unregistered: 16,
+			maxIncrease:  5,
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkUnregisteredSpike(&tt.state, tt.unregistered,
+				config.SafeguardsConfiguration{MaxUnregisteredIncrease: tt.maxIncrease})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkUnregisteredSpike() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, errSafeguardsExceeded) {
+				t.Errorf("checkUnregisteredSpike() error = %v, want %v", err, errSafeguardsExceeded)
+			}
+		})
+	}
+}
+
+func TestDetectTrackerOutages(t *testing.T) {
+	// torrents of the tracker with the given statuses
+	trackerTorrents := func(torrents map[string]config.Torrent, name string, statuses map[string]int) {
+		for status, n := range statuses {
+			for i := 0; i < n; i++ {
+				h := fmt.Sprintf("%s-%s-%d", name, status, i)
+				torrents[h] = config.Torrent{Hash: h, TrackerName: name, TrackerStatus: status}
+			}
+		}
+	}
+
+	torrents := make(map[string]config.Torrent)
+	trackerTorrents(torrents, "down", map[string]int{"Working": 5, "Tracker is down": 5})
+	trackerTorrents(torrents, "purged", map[string]int{"Working": 4, "Unregistered torrent": 6})
+	trackerTorrents(torrents, "healthy", map[string]int{"Working": 9, "Unregistered torrent": 1})
+	trackerTorrents(torrents, "small", map[string]int{"Tracker is down": 3})
+
+	// errored torrents per tracker of the previous run
+	previous := map[string]int{"down": 0, "purged": 1, "healthy": 1, "small": 0}
+
+	tests := []struct {
+		name         string
+		unregistered bool
+		previous     map[string]int
+		safeguards   config.SafeguardsConfiguration
+		want         []string
+		wantCounts   map[string]int
+	}{
+		{
+			name:         "no safeguard",
+			unregistered: true,
+			previous:     previous,
+			want:         []string{},
+		},
+		{
+			name:         "errored and unregistered",
+			unregistered: true,
+			previous:     previous,
+			safeguards:   config.SafeguardsConfiguration{MaxTrackerErrorPercent: 40},
+			want:         []string{"down", "purged"},
+			wantCounts:   map[string]int{"down": 0, "purged": 1, "healthy": 1, "small": 3},
+		},
+		{
+			name:       "unregistered statuses without unregistered filters",
+			previous:   previous,
+			safeguards: config.SafeguardsConfiguration{MaxTrackerErrorPercent: 40},
+			want:       []string{"down", "purged"},
+			wantCounts: map[string]int{"down": 0, "purged": 1, "healthy": 1, "small": 3},
+		},
+		{
+			name:         "at the limit",
+			unregistered: true,
+			previous:     previous,
+			safeguards:   config.SafeguardsConfiguration{MaxTrackerErrorPercent: 50},
+			want:         []string{},
+			wantCounts:   map[string]int{"down": 5, "purged": 6, "healthy": 1, "small": 3},
+		},
+		{
+			name:         "min tracker torrents",
+			unregistered: true,
+			previous:     previous,
+			safeguards:   config.SafeguardsConfiguration{MaxTrackerErrorPercent: 40, MinTrackerTorrents: 3},
+			want:         []string{"down", "purged", "small"},
+			wantCounts:   map[string]int{"down": 0, "purged": 1, "healthy": 1, "small": 0},
+		},
+		{
+			name:         "no previous run",
+			unregistered: true,
+			safeguards:   config.SafeguardsConfiguration{MaxTrackerErrorPercent: 40},
+			want:         []string{},
+			wantCounts:   map[string]int{"down": 5, "purged": 6, "healthy": 1, "small": 3},
+		},
+		{
+			name:         "errors of the previous run",
+			unregistered: true,
+			previous:     map[string]int{"down": 5, "purged": 6, "healthy": 1, "small": 3},
+			safeguards:   config.SafeguardsConfiguration{MaxTrackerErrorPercent: 40},
+			want:         []string{},
+			wantCounts:   map[string]int{"down": 5, "purged": 6, "healthy": 1, "small": 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suspended, counts := detectTrackerOutages(torrents, tt.unregistered, tt.previous, tt.safeguards)
+
+			got := make([]string, 0, len(suspended))
+			for name := range suspended {
+				got = append(got, name)
+			}
+			sort.Strings(got)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("detectTrackerOutages() = %v, want %v", suspended, tt.want)
+			}
+			if !reflect.DeepEqual(counts, tt.wantCounts) {
+				t.Errorf("detectTrackerOutages() counts = %v, want %v", counts, tt.wantCounts)
+			}
+		})
+	}
+
+	// unregistered torrents of suspended trackers are not counted
+	suspended, _ := detectTrackerOutages(torrents, true, previous, config.SafeguardsConfiguration{MaxTrackerErrorPercent: 40})
+	if got := countUnregisteredTorrents(torrents, suspended); got != 1 {
+		t.Errorf("countUnregisteredTorrents() = %d, want 1", got)
+	}
+	if got := countUnregisteredTorrents(torrents, nil); got != 7 {
+		t.Errorf("countUnregisteredTorrents() without suspended trackers = %d, want 7", got)
+	}
+}
+
+func TestRetryOperation(t *testing.T) {
+	retry := config.RetryConfiguration{Attempts: 3, Backoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	tests := []struct {
+		name      string
+		failures  int
+		cancel    bool
+		wantCalls int
+		wantErr   bool
+	}{
+		{name: "succeeds", failures: 0, wantCalls: 1},
+		{name: "succeeds after retries", failures: 2, wantCalls: 3},
+		{name: "attempts exhausted", failures: 5, wantCalls: 3, wantErr: true},
+		{name: "cancelled", failures: 5, cancel: true, wantCalls: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+
+			calls := 0
+			err := retryOperation(ctx, testLog(), retry, func() error {
+				calls++
+				if calls <= tt.failures {
+					return fmt.Errorf("attempt %d failed", calls)
+				}
+				return nil
+			})
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("retryOperation() error = %v, want error %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("retryOperation() calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if tt.wantErr && err.Error() != fmt.Sprintf("attempt %d failed", calls) {
+				t.Errorf("retryOperation() error = %v, want the last error", err)
+			}
+		})
+	}
+}
+
+func TestRelabelEligibleTorrents(t *testing.T) {
+	retry := config.RetryConfiguration{Attempts: 2, Backoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	tests := []struct {
+		name          string
+		labels        []string
+		failRelabels  int
+		failFast      bool
+		wantRelabeled int
+		wantIgnored   int
+		wantFailed    int
+		wantCalls     int
+	}{
+		{
+			name:          "relabels eligible torrents",
+			labels:        []string{"", "relabeled", "tv"},
+			wantRelabeled: 2,
+			wantIgnored:   1,
+			wantCalls:     2,
+		},
+		{
+			name:          "retries failed relabels",
+			labels:        []string{"", ""},
+			failRelabels:  1,
+			wantRelabeled: 2,
+			wantCalls:     3,
+		},
+		{
+			name:          "continues after failures",
+			labels:        []string{"", "", "error"},
+			failRelabels:  2,
+			wantRelabeled: 1,
+			wantFailed:    2,
+			wantCalls:     3,
+		},
+		{
+			name:         "fail fast",
+			labels:       []string{"", "", ""},
+			failRelabels: 2,
+			failFast:     true,
+			wantFailed:   1,
+			wantCalls:    2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flagFailFast = tt.failFast
+			defer func() {
+				flagFailFast = false
+			}()
+
+			c := &fakeClient{failRelabels: tt.failRelabels}
+			torrents := make(map[string]config.Torrent)
+			for i, label := range tt.labels {
+				h := fmt.Sprintf("hash%d", i)
+				torrents[h] = config.Torrent{Hash: h, Name: h, Label: label, Files: []string{"/data/" + h}}
+			}
+
+			result, err := relabelEligibleTorrents(context.Background(), testLog(), c, torrents,
+				torrentfilemap.New(torrents), retry)
+			if (err != nil) != (tt.wantFailed > 0) {
+				t.Fatalf("relabelEligibleTorrents() error = %v, want %d failures", err, tt.wantFailed)
+			}
+			if errs, ok := err.(operationErrors); ok && len(errs) != tt.wantFailed {
+				t.Errorf("relabelEligibleTorrents() errors = %d, want %d", len(errs), tt.wantFailed)
+			}
+
+			if result.Relabeled != tt.wantRelabeled || result.Ignored != tt.wantIgnored || result.Failed != tt.wantFailed {
+				t.Errorf("relabelEligibleTorrents() = %d relabeled, %d ignored, %d failed, want %d, %d, %d",
+					result.Relabeled, result.Ignored, result.Failed, tt.wantRelabeled, tt.wantIgnored, tt.wantFailed)
+			}
+			if len(c.calls) != tt.wantCalls {
+				t.Errorf("relabelEligibleTorrents() label calls = %d, want %d", len(c.calls), tt.wantCalls)
+			}
+		})
+	}
+}
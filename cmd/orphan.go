@@ -34,61 +34,60 @@ var orphanCmd = &cobra.Command{
 
 		// retrieve client object
 		clientName := args[0]
-		clientConfig, ok := config.Config.Clients[clientName]
-		if !ok {
-			log.Fatalf("No client configuration found for: %q", clientName)
+		summary := newRunSummary(log, "orphan", clientName)
+		clientConfig, err := config.GetClientConfiguration(clientName)
+		if err != nil {
+			summary.fatal(log, exitCodeError, err, "Failed loading client configuration: %q", clientName)
 		}
+		warnUnknownClientSettings(log, clientName)
 
 		// validate client is enabled
-		if err := validateClientEnabled(clientConfig); err != nil {
-			log.WithError(err).Fatal("Failed validating client is enabled")
+		if !clientConfig.Enabled {
+			summary.fatal(log, exitCodeError, nil, "Failed validating client is enabled")
 		}
 
-		// retrieve client type
-		clientType, err := getClientConfigString("type", clientConfig)
-		if err != nil {
-			log.WithError(err).Fatal("Failed determining client type")
-		}
+		// lock client
+		l := lockClient(log, summary)
+		defer l.Release()
 
 		// retrieve client download path
-		clientDownloadPath, err := getClientConfigString("download_path", clientConfig)
-		if err != nil {
-			log.WithError(err).Fatal("Failed determining client download path")
-		} else if clientDownloadPath == nil || *clientDownloadPath == "" {
-			log.Fatal("Client download path must be set...")
+		clientDownloadPath := clientConfig.DownloadPath
+		if clientDownloadPath == "" {
+			summary.fatal(log, exitCodeError, nil, "Client download path must be set...")
+		} else if err := clientConfig.CheckDownloadPath(); err != nil {
+			summary.fatal(log, exitCodeError, err, "Failed validating client download path")
 		}
 
 		// retrieve client download path mapping
-		clientDownloadPathMapping, err := getClientDownloadPathMapping(clientConfig)
-		if err != nil {
-			log.WithError(err).Fatal("Failed loading client download path mappings")
-		} else if clientDownloadPathMapping != nil {
+		clientDownloadPathMapping := clientConfig.DownloadPathMapping
+		if clientDownloadPathMapping != nil {
 			log.Debugf("Loaded %d client download path mappings: %#v", len(clientDownloadPathMapping),
 				clientDownloadPathMapping)
 		}
 
 		// load client object
-		c, err := client.NewClient(*clientType, clientName, nil)
+		c, err := client.NewClient(clientConfig.Type, clientName, nil)
 		if err != nil {
-			log.WithError(err).Fatalf("Failed initializing client: %q", clientName)
+			summary.fatal(log, exitCodeError, err, "Failed initializing client: %q", clientName)
 		}
 
 		log.Infof("Initialized client %q, type: %s (%d trackers)", clientName, c.Type(), tracker.Loaded())
 
 		// connect to client
-		if err := c.Connect(); err != nil {
-			log.WithError(err).Fatal("Failed connecting")
+		if err := c.Connect(ctx); err != nil {
+			summary.fatal(log, exitCodeConnect, err, "Failed connecting")
 		} else {
 			log.Debugf("Connected to client")
 		}
 
 		// retrieve torrents
-		torrents, err := c.GetTorrents()
+		torrents, err := c.GetTorrents(ctx)
 		if err != nil {
-			log.WithError(err).Fatal("Failed retrieving torrents")
+			summary.fatal(log, exitCodeConnect, err, "Failed retrieving torrents")
 		} else {
 			log.Infof("Retrieved %d torrents", len(torrents))
 		}
+		summary.Torrents = len(torrents)
 
 		if flagLogLevel > 1 {
 			if b, err := json.Marshal(torrents); err != nil {
@@ -103,9 +102,9 @@ var orphanCmd = &cobra.Command{
 		log.Infof("Mapped torrents to %d unique torrent files", tfm.Length())
 
 		// get all paths in client download location
-		localDownloadPaths, _ := paths.GetPathsInFolder(*clientDownloadPath, true, true,
+		localDownloadPaths, _ := paths.GetPathsInFolder(clientDownloadPath, true, true,
 			nil)
-		log.Tracef("Retrieved %d paths from: %q", len(localDownloadPaths), *clientDownloadPath)
+		log.Tracef("Retrieved %d paths from: %q", len(localDownloadPaths), clientDownloadPath)
 
 		// sort paths into their respective maps
 		localFilePaths := make(map[string]int64)
@@ -114,7 +113,7 @@ var orphanCmd = &cobra.Command{
 		for _, p := range localDownloadPaths {
 			p := p
 			if p.IsDir {
-				if strings.EqualFold(p.RealPath, *clientDownloadPath) {
+				if strings.EqualFold(p.RealPath, clientDownloadPath) {
 					// ignore root download path
 					continue
 				}
@@ -125,15 +124,21 @@ var orphanCmd = &cobra.Command{
 			}
 		}
 
-		log.Infof("Retrieved paths from %q: %d files / %d folders", *clientDownloadPath, len(localFilePaths),
+		log.Infof("Retrieved paths from %q: %d files / %d folders", clientDownloadPath, len(localFilePaths),
 			len(localFolderPaths))
 
 		// remove local files not associated with a torrent
-		removeFailures := 0
-		removedLocalFiles := 0
-		var removedLocalFilesSize uint64 = 0
+		result := &orphanResult{Actions: make([]torrentAction, 0)}
 
 		for localPath, localPathSize := range localFilePaths {
+			if flagFailFast && result.Failed > 0 {
+				log.Warn("Fail-fast enabled, skipping remaining orphans...")
+				break
+			} else if ctx.Err() != nil {
+				log.Warn("Interrupted, skipping remaining orphans...")
+				break
+			}
+
 			if tfm.HasPath(localPath, clientDownloadPathMapping) {
 				continue
 			} else {
@@ -141,6 +146,7 @@ var orphanCmd = &cobra.Command{
 
 				// file is not associated with a torrent
 				removed := true
+				action := torrentAction{Action: "remove_orphan_file", Path: localPath, Bytes: localPathSize}
 
 				log.Infof("Removing orphan: %q", localPath)
 				if flagDryRun {
@@ -149,24 +155,32 @@ var orphanCmd = &cobra.Command{
 					// remove file
 					if err := os.Remove(localPath); err != nil {
 						log.WithError(err).Errorf("Failed removing orphan...")
-						removeFailures++
+						action.Error = err.Error()
+						result.Failed++
 						removed = false
 					} else {
 						log.Info("Removed")
 					}
 				}
 
+				result.Actions = append(result.Actions, action)
 				if removed {
-					removedLocalFilesSize += uint64(localPathSize)
-					removedLocalFiles++
+					result.ReclaimedBytes += localPathSize
+					result.Files++
 				}
 			}
 		}
 
 		// remove local folders not associated with a torrent
-		removedLocalFolders := 0
-
 		for localPath := range localFolderPaths {
+			if flagFailFast && result.Failed > 0 {
+				log.Warn("Fail-fast enabled, skipping remaining orphans...")
+				break
+			} else if ctx.Err() != nil {
+				log.Warn("Interrupted, skipping remaining orphans...")
+				break
+			}
+
 			if tfm.HasPath(localPath, clientDownloadPathMapping) {
 				continue
 			} else {
@@ -174,6 +188,7 @@ var orphanCmd = &cobra.Command{
 
 				// folder is not associated with a torrent
 				removed := true
+				action := torrentAction{Action: "remove_orphan_folder", Path: localPath}
 
 				log.Infof("Removing orphan: %q", localPath)
 				if flagDryRun {
@@ -182,23 +197,27 @@ var orphanCmd = &cobra.Command{
 					// remove folder
 					if err := os.Remove(localPath); err != nil {
 						log.WithError(err).Errorf("Failed removing orphan...")
-						removeFailures++
+						action.Error = err.Error()
+						result.Failed++
 						removed = false
 					} else {
 						log.Info("Removed")
 					}
 				}
 
+				result.Actions = append(result.Actions, action)
 				if removed {
-					removedLocalFolders++
+					result.Folders++
 				}
 			}
 		}
 
 		log.Info("-----")
-		log.WithField("reclaimed_space", humanize.IBytes(removedLocalFilesSize)).
+		log.WithField("reclaimed_space", humanize.IBytes(uint64(result.ReclaimedBytes))).
 			Infof("Removed orphans: %d files, %d folders and %d failures",
-				removedLocalFiles, removedLocalFolders, removeFailures)
+				result.Files, result.Folders, result.Failed)
+
+		summary.finish(result)
 	},
 }
 
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
+	"time"
 
 	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
@@ -10,6 +12,7 @@ import (
 	"github.com/l3uddz/tqm/config"
 	"github.com/l3uddz/tqm/expression"
 	"github.com/l3uddz/tqm/logger"
+	"github.com/l3uddz/tqm/state"
 	"github.com/l3uddz/tqm/torrentfilemap"
 	"github.com/l3uddz/tqm/tracker"
 )
@@ -32,77 +35,75 @@ var relabelCmd = &cobra.Command{
 
 		// retrieve client object
 		clientName := args[0]
-		clientConfig, ok := config.Config.Clients[clientName]
-		if !ok {
-			log.Fatalf("No client configuration found for: %q", clientName)
+		summary := newRunSummary(log, "relabel", clientName)
+		clientConfig, err := config.GetClientConfiguration(clientName)
+		if err != nil {
+			summary.fatal(log, exitCodeError, err, "Failed loading client configuration: %q", clientName)
 		}
+		warnUnknownClientSettings(log, clientName)
 
 		// validate client is enabled
-		if err := validateClientEnabled(clientConfig); err != nil {
-			log.WithError(err).Fatal("Failed validating client is enabled")
+		if !clientConfig.Enabled {
+			summary.fatal(log, exitCodeError, nil, "Failed validating client is enabled")
 		}
 
-		// retrieve client type
-		clientType, err := getClientConfigString("type", clientConfig)
-		if err != nil {
-			log.WithError(err).Fatal("Failed determining client type")
-		}
-
-		// retrieve client free space path
-		clientFreeSpacePath, _ := getClientConfigString("free_space_path", clientConfig)
+		// lock client
+		l := lockClient(log, summary)
+		defer l.Release()
 
 		// retrieve client filters
-		clientFilter, err := getClientFilter(clientConfig)
+		clientFilter, err := config.MergeFilters(clientConfig.Filter...)
 		if err != nil {
-			log.WithError(err).Fatal("Failed retrieving client filter")
+			summary.fatal(log, exitCodeError, err, "Failed retrieving client filter")
 		}
 
 		if flagFilterName != "" {
 			clientFilter, err = getFilter(flagFilterName)
 			if err != nil {
-				log.WithError(err).Fatal("Failed retrieving specified filter")
+				summary.fatal(log, exitCodeError, err, "Failed retrieving specified filter")
 			}
 		}
 
 		// compile client filters
 		exp, err := expression.Compile(clientFilter)
 		if err != nil {
-			log.WithError(err).Fatal("Failed compiling client filters")
+			summary.fatal(log, exitCodeError, err, "Failed compiling client filters")
 		}
 
 		// load client object
-		c, err := client.NewClient(*clientType, clientName, exp)
+		c, err := client.NewClient(clientConfig.Type, clientName, exp)
 		if err != nil {
-			log.WithError(err).Fatalf("Failed initializing client: %q", clientName)
+			summary.fatal(log, exitCodeError, err, "Failed initializing client: %q", clientName)
 		}
 
 		log.Infof("Initialized client %q, type: %s (%d trackers)", clientName, c.Type(), tracker.Loaded())
 
 		// connect to client
-		if err := c.Connect(); err != nil {
-			log.WithError(err).Fatal("Failed connecting")
+		if err := c.Connect(ctx); err != nil {
+			summary.fatal(log, exitCodeConnect, err, "Failed connecting")
 		} else {
 			log.Debugf("Connected to client")
 		}
 
 		// get free disk space (can/will be used by filters)
-		if clientFreeSpacePath != nil {
-			space, err := c.GetCurrentFreeSpace(*clientFreeSpacePath)
+		if clientConfig.FreeSpacePath != "" {
+			space, err := c.GetCurrentFreeSpace(ctx, clientConfig.FreeSpacePath)
 			if err != nil {
-				log.WithError(err).Warnf("Failed retrieving free-space for: %q", *clientFreeSpacePath)
+				log.WithError(err).Warnf("Failed retrieving free-space for: %q", clientConfig.FreeSpacePath)
 			} else {
-				log.Infof("Retrieved free-space for %q: %v (%.2f GB)", *clientFreeSpacePath,
+				log.Infof("Retrieved free-space for %q: %v (%.2f GB)", clientConfig.FreeSpacePath,
 					humanize.IBytes(uint64(space)), c.GetFreeSpace())
 			}
 		}
 
 		// retrieve torrents
-		torrents, err := c.GetTorrents()
+		torrents, err := c.GetTorrents(ctx)
 		if err != nil {
-			log.WithError(err).Fatal("Failed retrieving torrents")
+			summary.fatal(log, exitCodeConnect, err, "Failed retrieving torrents")
 		} else {
 			log.Infof("Retrieved %d torrents", len(torrents))
 		}
+		summary.Torrents = len(torrents)
 
 		if flagLogLevel > 1 {
 			if b, err := json.Marshal(torrents); err != nil {
@@ -116,10 +117,31 @@ var relabelCmd = &cobra.Command{
 		tfm := torrentfilemap.New(torrents)
 		log.Infof("Mapped torrents to %d unique torrent files", tfm.Length())
 
+		// prefetch tracker data used by the filters, lookups not prefetched are made with the same context
+		config.SetLookupContext(ctx, torrents)
+		prefetchTrackerData(ctx, log, torrents, exp)
+
+		// torrents are only unregistered once their grace has passed (progress is only counted by clean)
+		if usesUnregistered(exp) {
+			config.CheckUnregisteredGrace(torrents, state.Client(clientName), time.Now())
+		}
+
 		// relabel torrents that meet the filter criteria
-		if err := relabelEligibleTorrents(log, c, torrents, tfm); err != nil {
-			log.WithError(err).Fatal("Failed relabeling eligible torrents...")
+		result, err := relabelEligibleTorrents(ctx, log, c, torrents, tfm, clientConfig.Retry)
+		var opErrs operationErrors
+		if errors.As(err, &opErrs) {
+			log.WithError(err).Errorf("Encountered %d failures while relabeling eligible torrents", len(opErrs))
+			summary.Error = err.Error()
+		} else if err != nil {
+			summary.fatal(log, exitCodeError, err, "Failed relabeling eligible torrents...")
 		}
+
+		// persist tracker cache
+		if err := tracker.SaveCache(); err != nil {
+			log.WithError(err).Error("Failed saving tracker cache")
+		}
+
+		summary.finish(result)
 	},
 }
 
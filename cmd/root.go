@@ -1,16 +1,21 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"github.com/l3uddz/tqm/notification"
 	"github.com/l3uddz/tqm/runtime"
+	"github.com/l3uddz/tqm/state"
 	"github.com/l3uddz/tqm/stringutils"
 	"github.com/l3uddz/tqm/tracker"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
+	"time"
 
 	"github.com/l3uddz/tqm/config"
 	"github.com/l3uddz/tqm/logger"
-	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -21,13 +26,22 @@ var (
 	flagConfigFile   = "config.yaml"
 	flagConfigFolder = config.GetDefaultConfigDirectory("tqm", flagConfigFile)
 	flagLogFile      = "activity.log"
+	flagStateFile    = "state.json"
 
-	flagFilterName string
-	flagDryRun     bool
+	flagLockTimeout time.Duration
+	flagOutput      = "text"
+
+	flagFilterName       string
+	flagDryRun           bool
+	flagFailFast         bool
+	flagIgnoreSafeguards bool
 
 	// Global vars
 	log         *logrus.Entry
 	initialized bool
+
+	// cancelled on interrupt, commands stop before the next torrent (or removal batch)
+	ctx = context.Background()
 )
 
 var rootCmd = &cobra.Command{
@@ -49,9 +63,13 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&flagConfigFolder, "config-dir", flagConfigFolder, "Config folder")
 	rootCmd.PersistentFlags().StringVarP(&flagConfigFile, "config", "c", flagConfigFile, "Config file")
 	rootCmd.PersistentFlags().StringVarP(&flagLogFile, "log", "l", flagLogFile, "Log file")
+	rootCmd.PersistentFlags().StringVar(&flagStateFile, "state", flagStateFile, "State file")
+	rootCmd.PersistentFlags().StringVar(&flagOutput, "output", flagOutput, "Output format of the run summary (text, json)")
+	rootCmd.PersistentFlags().DurationVar(&flagLockTimeout, "lock-timeout", 0, "Wait for another run on the same client to finish")
 	rootCmd.PersistentFlags().CountVarP(&flagLogLevel, "verbose", "v", "Verbose level")
 
 	rootCmd.PersistentFlags().BoolVar(&flagDryRun, "dry-run", false, "Dry run mode")
+	rootCmd.PersistentFlags().BoolVar(&flagFailFast, "fail-fast", false, "Abort the run on the first failed operation")
 }
 
 func initCore(showAppInfo bool) {
@@ -62,6 +80,9 @@ func initCore(showAppInfo bool) {
 	if !rootCmd.PersistentFlags().Changed("log") {
 		flagLogFile = filepath.Join(flagConfigFolder, flagLogFile)
 	}
+	if !rootCmd.PersistentFlags().Changed("state") {
+		flagStateFile = filepath.Join(flagConfigFolder, flagStateFile)
+	}
 
 	// Init Logging
 	if err := logger.Init(flagLogLevel, flagLogFile); err != nil {
@@ -70,16 +91,38 @@ func initCore(showAppInfo bool) {
 
 	log = logger.GetLogger("app")
 
+	// Init Signal Handling
+	var stop context.CancelFunc
+	ctx, stop = signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		// a second interrupt exits immediately
+		stop()
+		log.Warn("Interrupted, stopping before the next torrent (interrupt again to exit immediately)...")
+	}()
+
 	// Init Config
 	if err := config.Init(flagConfigFile); err != nil {
 		log.WithError(err).Fatal("Failed to initialize config")
 	}
 
 	// Init Trackers
+	if p := config.Config.Trackers.Cache.Path; p != "" && !filepath.IsAbs(p) {
+		config.Config.Trackers.Cache.Path = filepath.Join(flagConfigFolder, p)
+	}
+
 	if err := tracker.Init(config.Config.Trackers); err != nil {
 		log.WithError(err).Fatal("Failed to initialize trackers")
 	}
 
+	// Init Notifications
+	notification.Init(config.Config.Notifications)
+
+	// Init State
+	if err := state.Init(flagStateFile); err != nil {
+		log.WithError(err).Fatal("Failed to initialize state")
+	}
+
 	// Show App Info
 	if showAppInfo {
 		showUsing()
@@ -95,81 +138,6 @@ func showUsing() {
 	log.Info("------------------")
 }
 
-func validateClientEnabled(clientConfig map[string]interface{}) error {
-	v, ok := clientConfig["enabled"]
-	if !ok {
-		return fmt.Errorf("no enabled setting found in client configuration: %+v", clientConfig)
-	} else {
-		enabled, ok := v.(bool)
-		if !ok || !enabled {
-			return errors.New("client is not enabled")
-		}
-	}
-
-	return nil
-}
-
-func getClientConfigString(setting string, clientConfig map[string]interface{}) (*string, error) {
-	v, ok := clientConfig[setting]
-	if !ok {
-		return nil, fmt.Errorf("no %q setting found in client configuration: %+v", setting, clientConfig)
-	}
-
-	value, ok := v.(string)
-	if !ok {
-		return nil, fmt.Errorf("failed type-asserting %q of client: %#v", setting, v)
-	}
-
-	return &value, nil
-}
-
-func getClientDownloadPathMapping(clientConfig map[string]interface{}) (map[string]string, error) {
-	v, ok := clientConfig["download_path_mapping"]
-	if !ok {
-		return nil, nil
-	}
-
-	tmp, ok := v.(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("failed type-asserting download_path_mapping of client: %#v", v)
-	}
-
-	clientDownloadPathMapping := make(map[string]string)
-	for k, v := range tmp {
-		if vv, ok := v.(string); ok {
-			clientDownloadPathMapping[k] = vv
-		} else {
-			return nil, fmt.Errorf("failed type-asserting download_path_mapping of client for %q: %#v", k, v)
-		}
-	}
-
-	return clientDownloadPathMapping, nil
-}
-
-func getClientFilter(clientConfig map[string]interface{}) (*config.FilterConfiguration, error) {
-	v, ok := clientConfig["filter"]
-	if !ok {
-		return nil, fmt.Errorf("no filter setting found in client configuration: %+v", clientConfig)
-	}
-
-	clientFilterName, ok := v.(string)
-	if !ok {
-		return nil, fmt.Errorf("failed type-asserting filter of client: %#v", v)
-	}
-
-	clientFilter, ok := config.Config.Filters[clientFilterName]
-	if !ok {
-		return nil, fmt.Errorf("failed finding configuration of filter: %+v", clientFilterName)
-	}
-
-	return &clientFilter, nil
-}
-
 func getFilter(filterName string) (*config.FilterConfiguration, error) {
-	clientFilter, ok := config.Config.Filters[filterName]
-	if !ok {
-		return nil, fmt.Errorf("failed finding configuration of filter: %+v", filterName)
-	}
-
-	return &clientFilter, nil
+	return config.GetFilter(filterName)
 }
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/l3uddz/tqm/config"
+	"github.com/l3uddz/tqm/notification"
+	"github.com/l3uddz/tqm/tracker"
+)
+
+const (
+	exitCodeError          = 1
+	exitCodeLocked         = 2
+	exitCodePartialFailure = 3
+	exitCodeSafeguards     = 4
+	exitCodeConnect        = 5
+	exitCodeInterrupted    = 130
+)
+
+var (
+	errSafeguardsExceeded = errors.New("safeguards exceeded")
+)
+
+type runSummary struct {
+	Command      string             `json:"command"`
+	Client       string             `json:"client"`
+	DryRun       bool               `json:"dry_run"`
+	Status       string             `json:"status"`
+	ExitCode     int                `json:"exit_code"`
+	Error        string             `json:"error,omitempty"`
+	Torrents     int                `json:"torrents"`
+	Result       runResult          `json:"result,omitempty"`
+	TrackerCache *trackerCacheStats `json:"tracker_cache,omitempty"`
+}
+
+type trackerCacheStats struct {
+	Hits   int `json:"hits"`
+	Misses int `json:"misses"`
+}
+
+type runResult interface {
+	failures() int
+}
+
+type torrentAction struct {
+	Action  string `json:"action"`
+	Hash    string `json:"hash,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Label   string `json:"label,omitempty"`
+	Tracker string `json:"tracker,omitempty"`
+	Bytes   int64  `json:"bytes,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type removeResult struct {
+	Ignored        int             `json:"ignored"`
+	Suspended      int             `json:"suspended"`
+	HardRemoved    int             `json:"hard_removed"`
+	SoftRemoved    int             `json:"soft_removed"`
+	Failed         int             `json:"failed"`
+	ReclaimedBytes int64           `json:"reclaimed_bytes"`
+	Actions        []torrentAction `json:"actions"`
+}
+
+type relabelResult struct {
+	Ignored   int             `json:"ignored"`
+	NonUnique int             `json:"non_unique"`
+	Relabeled int             `json:"relabeled"`
+	Failed    int             `json:"failed"`
+	Actions   []torrentAction `json:"actions"`
+}
+
+type orphanResult struct {
+	Files          int             `json:"files"`
+	Folders        int             `json:"folders"`
+	Failed         int             `json:"failed"`
+	ReclaimedBytes int64           `json:"reclaimed_bytes"`
+	Actions        []torrentAction `json:"actions"`
+}
+
+func (r *removeResult) failures() int  { return r.Failed }
+func (r *relabelResult) failures() int { return r.Failed }
+func (r *orphanResult) failures() int  { return r.Failed }
+
+func newRunSummary(log *logrus.Entry, command string, clientName string) *runSummary {
+	s := &runSummary{
+		Command: command,
+		Client:  clientName,
+		DryRun:  flagDryRun,
+	}
+
+	if flagOutput != "text" && flagOutput != "json" {
+		s.fatal(log, exitCodeError, nil, "Unsupported output format: %q", flagOutput)
+	}
+
+	return s
+}
+
+// fatal logs the error and exits with the exit code
+func (s *runSummary) fatal(log *logrus.Entry, code int, err error, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	if err != nil {
+		log.WithError(err).Error(msg)
+		msg = fmt.Sprintf("%s: %v", msg, err)
+	} else {
+		log.Error(msg)
+	}
+
+	s.Error = msg
+	s.exit(code)
+}
+
+// finish completes the run with the result, exiting with a non-zero exit code when any action failed
+func (s *runSummary) finish(result runResult) {
+	s.Result = result
+
+	code := 0
+	if result.failures() > 0 {
+		code = exitCodePartialFailure
+	}
+
+	s.exit(code)
+}
+
+func (s *runSummary) exit(code int) {
+	if ctx.Err() != nil {
+		code = exitCodeInterrupted
+	}
+
+	// notifications are sent in the background, deliver them before exiting
+	notification.Wait()
+
+	s.ExitCode = code
+	s.Status = exitStatus(code)
+
+	if hits, misses := tracker.CacheStats(); hits+misses > 0 {
+		s.TrackerCache = &trackerCacheStats{Hits: hits, Misses: misses}
+	}
+
+	if flagOutput == "json" {
+		b, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			log.WithError(err).Error("Failed marshalling run summary")
+		} else {
+			fmt.Println(string(b))
+		}
+	}
+
+	if code != 0 {
+		os.Exit(code)
+	}
+}
+
+// exitStatus returns the status of the run summary for the exit code
+func exitStatus(code int) string {
+	switch code {
+	case 0:
+		return "success"
+	case exitCodePartialFailure:
+		return "partial_failure"
+	case exitCodeSafeguards:
+		return "aborted"
+	case exitCodeLocked:
+		return "locked"
+	case exitCodeInterrupted:
+		return "interrupted"
+	default:
+		return "failed"
+	}
+}
+
+func newTorrentAction(action string, t config.Torrent, err error) torrentAction {
+	a := torrentAction{
+		Action:  action,
+		Hash:    t.Hash,
+		Name:    t.Name,
+		Tracker: t.TrackerName,
+		Bytes:   t.DownloadedBytes,
+	}
+
+	if err != nil {
+		a.Error = err.Error()
+	}
+
+	return a
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/l3uddz/tqm/config"
+)
+
+func TestExitStatus(t *testing.T) {
+	tests := []struct {
+		code int
+		want string
+	}{
+		{code: 0, want: "success"},
+		{code: exitCodeError, want: "failed"},
+		{code: exitCodeLocked, want: "locked"},
+		{code: exitCodePartialFailure, want: "partial_failure"},
+		{code: exitCodeSafeguards, want: "aborted"},
+		{code: exitCodeConnect, want: "failed"},
+		{code: exitCodeInterrupted, want: "interrupted"},
+	}
+
+	for _, tt := range tests {
+		if got := exitStatus(tt.code); got != tt.want {
+			t.Errorf("exitStatus(%d) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestRunSummaryFinish(t *testing.T) {
+	s := newRunSummary(testLog(), "clean", "qbt")
+
+	result := &removeResult{HardRemoved: 2}
+	s.finish(result)
+
+	if s.ExitCode != 0 || s.Status != "success" {
+		t.Errorf("finish() = %d (%s), want 0 (success)", s.ExitCode, s.Status)
+	}
+	if s.Result != result {
+		t.Errorf("finish() result = %v, want %v", s.Result, result)
+	}
+}
+
+func TestNewTorrentAction(t *testing.T) {
+	torrent := config.Torrent{Hash: "hash", Name: "name", TrackerName: "bhd", DownloadedBytes: 10}
+
+	want := torrentAction{Action: "remove", Hash: "hash", Name: "name", Tracker: "bhd", Bytes: 10, Error: "failed"}
+	if got := newTorrentAction("remove", torrent, errors.New("failed")); got != want {
+		t.Errorf("newTorrentAction() = %+v, want %+v", got, want)
+	}
+
+	want.Error = ""
+	if got := newTorrentAction("remove", torrent, nil); got != want {
+		t.Errorf("newTorrentAction() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/knadh/koanf"
+	"github.com/mitchellh/mapstructure"
+)
+
+type ClientConfiguration struct {
+	Enabled             bool
+	Type                string            `validate:"required,oneof=deluge qbittorrent"`
+	Filter              []string          `validate:"required"`
+	DownloadPath        string            `koanf:"download_path"`
+	DownloadPathMapping map[string]string `koanf:"download_path_mapping"`
+	FreeSpacePath       string            `koanf:"free_space_path"`
+	ConnectTimeout      time.Duration     `koanf:"connect_timeout" validate:"duration"`
+	RequestTimeout      time.Duration     `koanf:"request_timeout" validate:"duration"`
+	Safeguards          SafeguardsConfiguration
+	Retry               RetryConfiguration
+	Removal             RemovalConfiguration
+}
+
+type DelugeConfiguration struct {
+	ClientConfiguration `koanf:",squash"`
+
+	Host     string `validate:"required"`
+	Port     uint   `validate:"required,min=1,max=65535"`
+	Login    string `validate:"required"`
+	Password string `validate:"required"`
+	V2       bool
+}
+
+type QBittorrentConfiguration struct {
+	ClientConfiguration `koanf:",squash"`
+
+	Url      string `validate:"required,url"`
+	User     string
+	Password string
+}
+
+/* Vars */
+
+var (
+	clientConfigurations = map[string]func() interface{}{
+		"deluge":      func() interface{} { return new(DelugeConfiguration) },
+		"qbittorrent": func() interface{} { return new(QBittorrentConfiguration) },
+	}
+
+	defaultConnectTimeout = 30 * time.Second
+	defaultRequestTimeout = 2 * time.Minute
+)
+
+/* Public */
+
+// GetClientConfiguration returns the client configuration, after validating it against the schema of its type
+func GetClientConfiguration(name string) (*ClientConfiguration, error) {
+	c, ok := Config.Clients[name]
+	if !ok {
+		return nil, fmt.Errorf("no client configuration found for: %q", name)
+	}
+
+	newTyped, ok := clientConfigurations[strings.ToLower(c.Type)]
+	if !ok {
+		return nil, fmt.Errorf("client type not implemented: %q", c.Type)
+	}
+
+	if err := UnmarshalClientConfiguration(name, newTyped()); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// UnknownClientSettings returns the settings of the client configuration not known by the schema of its type, e.g.
+// misspelled settings (which are otherwise ignored)
+func UnknownClientSettings(name string) ([]string, error) {
+	c, ok := Config.Clients[name]
+	if !ok {
+		return nil, fmt.Errorf("no client configuration found for: %q", name)
+	}
+
+	newTyped, ok := clientConfigurations[strings.ToLower(c.Type)]
+	if !ok {
+		return nil, fmt.Errorf("client type not implemented: %q", c.Type)
+	}
+
+	unused, err := unmarshalClientConfiguration(name, newTyped())
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(unused)
+	return unused, nil
+}
+
+// UnmarshalClientConfiguration unmarshals the client configuration into the typed configuration and validates it
+func UnmarshalClientConfiguration(name string, typed interface{}) error {
+	if _, err := unmarshalClientConfiguration(name, typed); err != nil {
+		return err
+	}
+
+	if errs := ValidateStruct(typed); errs != nil {
+		return fmt.Errorf("validate config: %w", ValidationErrors(errs))
+	}
+
+	return nil
+}
+
+// CheckDownloadPath returns an error when the download path is set but does not exist, it is only checked by the
+// commands using it, as the path may not be mounted where the other commands run
+func (c ClientConfiguration) CheckDownloadPath() error {
+	if _, err := (PathExistsValidator{}).Validate(reflect.ValueOf(c.DownloadPath)); err != nil {
+		return fmt.Errorf("download_path: %w", err)
+	}
+
+	return nil
+}
+
+// Timeouts returns the timeouts for connecting to the client and for each request to it
+func (c ClientConfiguration) Timeouts() (time.Duration, time.Duration) {
+	connect, request := c.ConnectTimeout, c.RequestTimeout
+	if connect == 0 {
+		connect = defaultConnectTimeout
+	}
+	if request == 0 {
+		request = defaultRequestTimeout
+	}
+
+	return connect, request
+}
+
+/* Private */
+
+// unmarshalClientConfiguration unmarshals the client configuration into the typed configuration (decoded like
+// koanf.Unmarshal), returning the settings not known by the typed configuration
+func unmarshalClientConfiguration(name string, typed interface{}) ([]string, error) {
+	var md mapstructure.Metadata
+	if err := K.UnmarshalWithConf(fmt.Sprintf("clients%s%s", Delimiter, name), typed, koanf.UnmarshalConf{
+		DecoderConfig: &mapstructure.DecoderConfig{
+			DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+			Metadata:         &md,
+			Result:           typed,
+			WeaklyTypedInput: true,
+		},
+	}); err != nil {
+		return nil, fmt.Errorf("unmarshal config: %w", err)
+	}
+
+	// nested settings are prefixed with the field names (e.g. Safeguards.max_removed_gb), which match the setting
+	// names case-insensitively
+	unused := make([]string, 0, len(md.Unused))
+	for _, key := range md.Unused {
+		if i := strings.LastIndex(key, "."); i >= 0 {
+			key = strings.ToLower(key[:i]) + key[i:]
+		}
+		unused = append(unused, key)
+	}
+
+	return unused, nil
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnknownClientSettings(t *testing.T) {
+	setTestConfig(t, map[string]interface{}{
+		"clients.qbt.type":                        "qbittorrent",
+		"clients.qbt.url":                         "http://localhost:8080",
+		"clients.qbt.pasword":                     "typo",
+		"clients.qbt.request_timeout":             "1m",
+		"clients.qbt.safeguards.max_removed":      10,
+		"clients.qbt.safeguards.max_removed_gb":   100,
+		"clients.qbt.removal.strategy":            "none",
+		"clients.deluge.type":                     "deluge",
+		"clients.deluge.host":                     "localhost",
+		"clients.deluge.v2":                       true,
+		"clients.deluge.url":                      "http://localhost:8112",
+		"clients.deluge.removal.trackers":         []interface{}{map[string]interface{}{"trackers": []string{"bhd"}, "stratgy": "none"}},
+		"clients.unknown.type":                    "rtorrent",
+		"clients.unknown.removal.reannounce_dely": "1s",
+	})
+
+	prev := Config
+	Config = &Configuration{Clients: map[string]ClientConfiguration{
+		"qbt":     {Type: "qbittorrent"},
+		"deluge":  {Type: "Deluge"},
+		"unknown": {Type: "rtorrent"},
+	}}
+	t.Cleanup(func() {
+		Config = prev
+	})
+
+	tests := []struct {
+		name    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "qbt", want: []string{"pasword", "safeguards.max_removed_gb"}},
+		{name: "deluge", want: []string{"removal.trackers[0].stratgy", "url"}},
+		{name: "unknown", wantErr: true},
+		{name: "missing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnknownClientSettings(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnknownClientSettings() error = %v, want error: %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnknownClientSettings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
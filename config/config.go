@@ -2,29 +2,37 @@ package config
 
 import (
 	"fmt"
+	"sync"
+
 	"github.com/knadh/koanf"
-	"github.com/knadh/koanf/parsers/yaml"
-	"github.com/knadh/koanf/providers/file"
 	"github.com/l3uddz/tqm/logger"
+	"github.com/l3uddz/tqm/notification"
 	"github.com/l3uddz/tqm/stringutils"
 	"github.com/l3uddz/tqm/tracker"
 )
 
 type Configuration struct {
-	Clients  map[string]map[string]interface{}
-	Filters  map[string]FilterConfiguration
-	Trackers tracker.Config
+	Include           []string                       // consumed while loading the configuration files
+	Clients           map[string]ClientConfiguration `validate:"-"`
+	Filters           map[string]FilterConfiguration
+	Trackers          tracker.Config
+	TrackerErrors     TrackerErrorsConfiguration     `koanf:"tracker_errors"`
+	UnregisteredGrace UnregisteredGraceConfiguration `koanf:"unregistered_grace"`
+	Notifications     notification.Config
 }
 
 /* Vars */
 
 var (
-	cfgPath = ""
+	cfgPath  = ""
+	cfgFiles []string
 
 	Delimiter = "."
 	Config    *Configuration
 	K         = koanf.New(Delimiter)
 
+	reloadMtx sync.Mutex
+
 	// Internal
 	log = logger.GetLogger("cfg")
 )
@@ -35,20 +43,95 @@ func Init(configFilePath string) error {
 	// set package variables
 	cfgPath = configFilePath
 
-	// load config
-	if err := K.Load(file.Provider(configFilePath), yaml.Parser()); err != nil {
-		return fmt.Errorf("load: %w", err)
+	return Reload(nil)
+}
+
+// Reload loads the configuration files again, validating the configuration and checking it with the check function
+// (e.g. compiling its filters) before replacing the current configuration, which is kept when any step failed
+func Reload(check func(cfg *Configuration) error) error {
+	reloadMtx.Lock()
+	defer reloadMtx.Unlock()
+
+	l, err := load(cfgPath)
+	if err != nil {
+		return err
 	}
 
-	// unmarshal config
-	if err := K.Unmarshal("", &Config); err != nil {
-		return fmt.Errorf("unmarshal: %w", err)
+	if check != nil {
+		if err := check(l.config); err != nil {
+			return fmt.Errorf("check: %w", err)
+		}
 	}
 
+	// swap in the new configuration
+	K = l.k
+	Config = l.config
+	cfgFiles = l.files
+	trackerErrorCategories = l.trackerErrorCategories
+	trackerErrorOverrides = l.trackerErrorOverrides
+
 	return nil
 }
 
 func ShowUsing() {
 	log.Infof("Using %s = %q", stringutils.LeftJust("CONFIG", " ", 10), cfgPath)
+	if len(cfgFiles) > 1 {
+		log.Infof("Using %s = %d files", stringutils.LeftJust("INCLUDES", " ", 10), len(cfgFiles)-1)
+	}
+
+}
+
+/* Private */
+
+type loadedConfiguration struct {
+	k      *koanf.Koanf
+	config *Configuration
+	files  []string
+
+	trackerErrorCategories []trackerErrorCategory
+	trackerErrorOverrides  []trackerErrorOverride
+}
+
+// load parses and validates the configuration, without changing the current configuration
+func load(configFilePath string) (*loadedConfiguration, error) {
+	l := &loadedConfiguration{k: koanf.New(Delimiter)}
+
+	// load config
+	files, err := loadFiles(l.k, configFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("load: %w", err)
+	}
+	l.files = files
+
+	// load environment overrides
+	if err := loadEnv(l.k); err != nil {
+		return nil, fmt.Errorf("load env: %w", err)
+	}
+
+	if err := interpolateEnv(l.k); err != nil {
+		return nil, fmt.Errorf("interpolate env: %w", err)
+	}
+
+	// load secrets from files
+	if err := loadSecretFiles(l.k); err != nil {
+		return nil, fmt.Errorf("load secret files: %w", err)
+	}
+
+	// unmarshal config
+	if err := l.k.Unmarshal("", &l.config); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+
+	// validate config (clients are validated against the schema of their type when used)
+	if errs := ValidateStruct(l.config); errs != nil {
+		return nil, fmt.Errorf("validate: %w", ValidationErrors(errs))
+	}
+
+	// compile tracker error patterns
+	l.trackerErrorCategories, l.trackerErrorOverrides, err = compileTrackerErrors(l.config.TrackerErrors)
+	if err != nil {
+		return nil, fmt.Errorf("tracker errors: %w", err)
+	}
 
+	return l, nil
 }
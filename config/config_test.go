@@ -0,0 +1,126 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReload(t *testing.T) {
+	// restore the configuration of other tests
+	prevK, prevConfig, prevPath, prevFiles := K, Config, cfgPath, cfgFiles
+	prevCategories, prevOverrides := trackerErrorCategories, trackerErrorOverrides
+	t.Cleanup(func() {
+		K, Config, cfgPath, cfgFiles = prevK, prevConfig, prevPath, prevFiles
+		trackerErrorCategories, trackerErrorOverrides = prevCategories, prevOverrides
+	})
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+	write := func(data string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`filters:
+  default:
+    remove:
+      - Ratio > 1
+`)
+	if err := Init(path); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	// keeps the current configuration when the new configuration is invalid or fails the check
+	tests := []struct {
+		name    string
+		data    string
+		check   func(cfg *Configuration) error
+		wantErr string
+	}{
+		{
+			name:    "invalid yaml",
+			data:    "filters: [",
+			wantErr: "load",
+		},
+		{
+			name:    "invalid settings",
+			data:    "trackers:\n  policies:\n    - min_ratio: 1\n",
+			wantErr: "validate",
+		},
+		{
+			name:    "invalid tracker errors",
+			data:    "tracker_errors:\n  categories:\n    down:\n      - 'regex:('\n",
+			wantErr: "tracker errors",
+		},
+		{
+			name: "failed check",
+			data: "filters:\n  default:\n    remove:\n      - Ratio >\n",
+			check: func(cfg *Configuration) error {
+				return errors.New("compile filter")
+			},
+			wantErr: "check: compile filter",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			write(tt.data)
+
+			err := Reload(tt.check)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Reload() error = %v, want containing %q", err, tt.wantErr)
+			}
+
+			if f, err := GetFilter("default"); err != nil || len(f.Remove) != 1 || f.Remove[0] != "Ratio > 1" {
+				t.Errorf("GetFilter() = %+v, %v, want the previous filter", f, err)
+			}
+			if got := K.Strings("filters.default.remove"); len(got) != 1 || got[0] != "Ratio > 1" {
+				t.Errorf("K = %v, want the previous configuration", got)
+			}
+		})
+	}
+
+	// swaps in the new configuration once it passed the check
+	write(`filters:
+  default:
+    remove:
+      - Ratio > 2
+  extended:
+    extends:
+      - default
+    remove:
+      - SeedingDays > 30
+tracker_errors:
+  categories:
+    down:
+      - maintenance
+`)
+
+	checked := 0
+	err := Reload(func(cfg *Configuration) error {
+		// filters are resolved against the new configuration
+		f, err := cfg.GetFilter("extended")
+		if err != nil {
+			return err
+		}
+		checked = len(f.Remove)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if checked != 2 {
+		t.Errorf("Reload() checked %d remove expressions, want 2", checked)
+	}
+
+	if f, err := GetFilter("default"); err != nil || len(f.Remove) != 1 || f.Remove[0] != "Ratio > 2" {
+		t.Errorf("GetFilter() = %+v, %v, want the new filter", f, err)
+	}
+	if got := GetTrackerErrorCategory("", "", "Site maintenance"); got != TrackerErrorDown {
+		t.Errorf("GetTrackerErrorCategory() = %q, want %q", got, TrackerErrorDown)
+	}
+}
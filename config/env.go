@@ -0,0 +1,122 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/knadh/koanf"
+	"github.com/knadh/koanf/providers/confmap"
+	"github.com/knadh/koanf/providers/env"
+)
+
+const (
+	envPrefix         = "TQM__"
+	envDelimiter      = "__"
+	secretFileSuffix  = "_file"
+	interpolateFormat = `\$\{([A-Za-z_][A-Za-z0-9_]*)\}`
+)
+
+var (
+	interpolateRegex = regexp.MustCompile(interpolateFormat)
+)
+
+/* Private */
+
+// loadEnv overrides settings with TQM__ prefixed environment variables, e.g. TQM__CLIENTS__QBT__PASSWORD
+func loadEnv(k *koanf.Koanf) error {
+	// existing keys keep their case, e.g. client names
+	existing := make(map[string]string)
+	for path := range k.KeyMap() {
+		existing[strings.ToLower(path)] = path
+	}
+
+	return k.Load(env.Provider(envPrefix, Delimiter, func(s string) string {
+		parts := strings.Split(strings.ToLower(strings.TrimPrefix(s, envPrefix)), envDelimiter)
+		for i := len(parts); i > 0; i-- {
+			if path, ok := existing[strings.Join(parts[:i], Delimiter)]; ok {
+				return strings.Join(append([]string{path}, parts[i:]...), Delimiter)
+			}
+		}
+
+		return strings.Join(parts, Delimiter)
+	}), nil)
+}
+
+// interpolateEnv replaces ${ENV} references within settings with the value of the environment variable
+func interpolateEnv(k *koanf.Koanf) error {
+	var errs []error
+	interpolate := func(path string, s string) string {
+		return interpolateRegex.ReplaceAllStringFunc(s, func(ref string) string {
+			name := interpolateRegex.FindStringSubmatch(ref)[1]
+			v, ok := os.LookupEnv(name)
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s: environment variable not set: %s", path, name))
+			}
+			return v
+		})
+	}
+
+	interpolated := make(map[string]interface{})
+	for path, v := range k.All() {
+		switch value := v.(type) {
+		case string:
+			if s := interpolate(path, value); s != value {
+				interpolated[path] = s
+			}
+		case []interface{}:
+			changed := false
+			values := make([]interface{}, 0, len(value))
+			for _, item := range value {
+				if s, ok := item.(string); ok {
+					if is := interpolate(path, s); is != s {
+						item = is
+						changed = true
+					}
+				}
+				values = append(values, item)
+			}
+
+			if changed {
+				interpolated[path] = values
+			}
+		}
+	}
+
+	if errs != nil {
+		return ValidationErrors(errs)
+	}
+
+	return k.Load(confmap.Provider(interpolated, Delimiter), nil)
+}
+
+// loadSecretFiles sets settings from the files of their _file variants, e.g. password_file
+func loadSecretFiles(k *koanf.Koanf) error {
+	paths := make([]string, 0)
+	for path := range k.All() {
+		if strings.HasSuffix(path, secretFileSuffix) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	secrets := make(map[string]interface{})
+	for _, path := range paths {
+		setting := strings.TrimSuffix(path, secretFileSuffix)
+		if k.Exists(setting) {
+			return fmt.Errorf("%s: both %s and %s are set", setting, setting, path)
+		}
+
+		b, err := os.ReadFile(k.String(path))
+		if err != nil {
+			return fmt.Errorf("%s: read secret: %w", path, err)
+		}
+
+		secrets[setting] = strings.TrimRight(string(b), "\r\n")
+		k.Delete(path)
+	}
+
+	return k.Load(confmap.Provider(secrets, Delimiter), nil)
+}
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/knadh/koanf"
+	"github.com/knadh/koanf/providers/confmap"
+)
+
+// setTestConfig replaces the loaded settings for the duration of the test
+func setTestConfig(t *testing.T, settings map[string]interface{}) {
+	t.Helper()
+
+	k := koanf.New(Delimiter)
+	if err := k.Load(confmap.Provider(settings, Delimiter), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	prev := K
+	K = k
+	t.Cleanup(func() {
+		K = prev
+	})
+}
+
+func TestLoadEnv(t *testing.T) {
+	setTestConfig(t, map[string]interface{}{
+		"clients.MyQbt.password": "old",
+		"clients.MyQbt.url":      "http://localhost:8080",
+	})
+
+	t.Setenv("TQM__CLIENTS__MYQBT__PASSWORD", "new")
+	t.Setenv("TQM__TRACKERS__BHD__API_KEY", "key")
+
+	if err := loadEnv(K); err != nil {
+		t.Fatalf("loadEnv() error = %v", err)
+	}
+
+	want := map[string]string{
+		"clients.MyQbt.password": "new",
+		"clients.MyQbt.url":      "http://localhost:8080",
+		"trackers.bhd.api_key":   "key",
+	}
+	for path, v := range want {
+		if got := K.String(path); got != v {
+			t.Errorf("loadEnv() %s = %q, want %q", path, got, v)
+		}
+	}
+	if K.Exists("clients.myqbt") {
+		t.Errorf("loadEnv() created lowercase client: %v", K.Get("clients.myqbt"))
+	}
+}
+
+func TestInterpolateEnv(t *testing.T) {
+	t.Setenv("TQM_TEST_HOST", "localhost")
+	t.Setenv("TQM_TEST_EMPTY", "")
+
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		want     map[string]interface{}
+		wantErr  string
+	}{
+		{
+			name:     "string",
+			settings: map[string]interface{}{"clients.qbt.url": "http://${TQM_TEST_HOST}:8080"},
+			want:     map[string]interface{}{"clients.qbt.url": "http://localhost:8080"},
+		},
+		{
+			name:     "list",
+			settings: map[string]interface{}{"filters.default.remove": []interface{}{"Seeds > 1", "Label == \"${TQM_TEST_HOST}\""}},
+			want:     map[string]interface{}{"filters.default.remove": []interface{}{"Seeds > 1", "Label == \"localhost\""}},
+		},
+		{
+			name:     "empty variable",
+			settings: map[string]interface{}{"clients.qbt.user": "${TQM_TEST_EMPTY}"},
+			want:     map[string]interface{}{"clients.qbt.user": ""},
+		},
+		{
+			name:     "not a reference",
+			settings: map[string]interface{}{"clients.qbt.password": "$TQM_TEST_HOST ${1INVALID}"},
+			want:     map[string]interface{}{"clients.qbt.password": "$TQM_TEST_HOST ${1INVALID}"},
+		},
+		{
+			name: "unset variables",
+			settings: map[string]interface{}{
+				"clients.qbt.url":  "http://${TQM_TEST_UNSET}:8080",
+				"clients.qbt.user": "${TQM_TEST_HOST}",
+			},
+			wantErr: "clients.qbt.url: environment variable not set: TQM_TEST_UNSET",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestConfig(t, tt.settings)
+
+			err := interpolateEnv(K)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("interpolateEnv() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("interpolateEnv() error = %v", err)
+			}
+
+			for path, v := range tt.want {
+				if got := K.Get(path); !reflect.DeepEqual(got, v) {
+					t.Errorf("interpolateEnv() %s = %#v, want %#v", path, got, v)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadSecretFiles(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "secret")
+	if err := os.WriteFile(secret, []byte("hunter2\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		want     map[string]string
+		wantErr  string
+	}{
+		{
+			name:     "reads secret",
+			settings: map[string]interface{}{"clients.qbt.password_file": secret},
+			want:     map[string]string{"clients.qbt.password": "hunter2"},
+		},
+		{
+			name: "both set",
+			settings: map[string]interface{}{
+				"clients.qbt.password":      "plain",
+				"clients.qbt.password_file": secret,
+			},
+			wantErr: "clients.qbt.password: both clients.qbt.password and clients.qbt.password_file are set",
+		},
+		{
+			name:     "missing file",
+			settings: map[string]interface{}{"trackers.bhd.api_key_file": filepath.Join(dir, "missing")},
+			wantErr:  "trackers.bhd.api_key_file: read secret",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestConfig(t, tt.settings)
+
+			err := loadSecretFiles(K)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("loadSecretFiles() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadSecretFiles() error = %v", err)
+			}
+
+			for path, v := range tt.want {
+				if got := K.String(path); got != v {
+					t.Errorf("loadSecretFiles() %s = %q, want %q", path, got, v)
+				}
+				if K.Exists(path + secretFileSuffix) {
+					t.Errorf("loadSecretFiles() kept %s%s", path, secretFileSuffix)
+				}
+			}
+		})
+	}
+}
@@ -1,10 +1,145 @@
 package config
 
+import (
+	"fmt"
+	"strings"
+
+	"github.com/l3uddz/tqm/sliceutils"
+)
+
 type FilterConfiguration struct {
-	Ignore []string
-	Remove []string
-	Label  []struct {
-		Name   string
-		Update []string
+	Extends    []string                   `yaml:"extends,omitempty"`
+	Override   []string                   `yaml:"override,omitempty"`
+	Macros     map[string]string          `yaml:"macros,omitempty"`
+	Safeguards SafeguardsConfiguration    `yaml:"safeguards,omitempty"`
+	Ignore     []string                   `yaml:"ignore,omitempty"`
+	Remove     []string                   `yaml:"remove,omitempty"`
+	Label      []FilterLabelConfiguration `yaml:"label,omitempty"`
+}
+
+type FilterLabelConfiguration struct {
+	Name   string   `yaml:"name"`
+	Update []string `yaml:"update"`
+}
+
+/* Public */
+
+// GetFilter returns the filter with its extended filters merged in
+func GetFilter(name string) (*FilterConfiguration, error) {
+	return Config.GetFilter(name)
+}
+
+// GetFilter returns the filter of the configuration, with the filters it extends merged in
+func (c *Configuration) GetFilter(name string) (*FilterConfiguration, error) {
+	return resolveFilter(c.Filters, name, nil)
+}
+
+// MergeFilters returns the filters merged in order, later filters extend the earlier ones
+func MergeFilters(names ...string) (*FilterConfiguration, error) {
+	merged := new(FilterConfiguration)
+	for _, name := range names {
+		f, err := GetFilter(name)
+		if err != nil {
+			return nil, err
+		}
+
+		merged.merge(f)
+	}
+
+	return merged, nil
+}
+
+/* Private */
+
+func resolveFilter(filters map[string]FilterConfiguration, name string,
+	resolving []string) (*FilterConfiguration, error) {
+	// detect filters extending themselves
+	for i, n := range resolving {
+		if n == name {
+			cycle := append(append([]string{}, resolving[i:]...), name)
+			return nil, fmt.Errorf("filter %q: extends cycle: %s", name, strings.Join(cycle, " -> "))
+		}
+	}
+
+	filter, ok := filters[name]
+	if !ok {
+		return nil, fmt.Errorf("failed finding configuration of filter: %+v", name)
+	}
+
+	// merge extended filters in order
+	resolved := new(FilterConfiguration)
+	for _, base := range filter.Extends {
+		b, err := resolveFilter(filters, base, append(resolving, name))
+		if err != nil {
+			return nil, err
+		}
+
+		resolved.merge(b)
+	}
+
+	// keep the overrides so merging the resolved filter after others applies them
+	resolved.merge(&filter)
+	resolved.Override = filter.Override
+	return resolved, nil
+}
+
+// merge appends the sections of the other filter, unless the other filter overrides them
+func (f *FilterConfiguration) merge(o *FilterConfiguration) {
+	overrides := func(section string) bool {
+		return sliceutils.StringSliceContains(o.Override, section, true)
+	}
+
+	// macros
+	if overrides("macros") {
+		f.Macros = nil
+	}
+	if len(o.Macros) > 0 && f.Macros == nil {
+		f.Macros = make(map[string]string)
+	}
+	for name, macro := range o.Macros {
+		f.Macros[name] = macro
+	}
+
+	// safeguards (extending filters can only make them stricter, unless overridden)
+	if overrides("safeguards") {
+		f.Safeguards = SafeguardsConfiguration{}
+	}
+	f.Safeguards = f.Safeguards.Stricter(o.Safeguards)
+
+	// ignores
+	if overrides("ignore") {
+		f.Ignore = nil
+	}
+	f.Ignore = append(f.Ignore, o.Ignore...)
+
+	// removes
+	if overrides("remove") {
+		f.Remove = nil
+	}
+	f.Remove = append(f.Remove, o.Remove...)
+
+	// labels (replacing labels with the same name in place)
+	if overrides("label") {
+		f.Label = nil
+	}
+	for _, label := range o.Label {
+		replaced := false
+		for i, existing := range f.Label {
+			if existing.Name == label.Name {
+				f.Label[i] = FilterLabelConfiguration{
+					Name:   label.Name,
+					Update: append([]string{}, label.Update...),
+				}
+				replaced = true
+				break
+			}
+		}
+
+		if !replaced {
+			f.Label = append(f.Label, FilterLabelConfiguration{
+				Name:   label.Name,
+				Update: append([]string{}, label.Update...),
+			})
+		}
 	}
 }
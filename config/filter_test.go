@@ -0,0 +1,165 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetFilter(t *testing.T) {
+	filters := map[string]FilterConfiguration{
+		"base": {
+			Macros:     map[string]string{"Old": "SeedingDays > 30"},
+			Safeguards: SafeguardsConfiguration{MaxRemoved: 10},
+			Ignore:     []string{"IsTrackerDown()"},
+			Remove:     []string{"IsUnregistered()"},
+			Label: []FilterLabelConfiguration{
+				{Name: "permaseed", Update: []string{"Seeds < 3"}},
+			},
+		},
+		"extended": {
+			Extends:    []string{"base"},
+			Macros:     map[string]string{"Old": "SeedingDays > 60", "Small": "TotalBytes < 1"},
+			Safeguards: SafeguardsConfiguration{MaxRemoved: 20, MaxRemovedPercent: 5},
+			Remove:     []string{"Old"},
+			Label: []FilterLabelConfiguration{
+				{Name: "permaseed", Update: []string{"Seeds < 5"}},
+				{Name: "archive", Update: []string{"Old"}},
+			},
+		},
+		"overridden": {
+			Extends:    []string{"base"},
+			Override:   []string{"remove", "safeguards", "label"},
+			Safeguards: SafeguardsConfiguration{MaxRemoved: 50},
+			Remove:     []string{"Ratio > 4"},
+		},
+		"multiple": {
+			Extends: []string{"extended", "overridden"},
+		},
+		"self": {
+			Extends: []string{"self"},
+		},
+		"loop-a": {
+			Extends: []string{"loop-b"},
+		},
+		"loop-b": {
+			Extends: []string{"loop-a"},
+		},
+		"missing": {
+			Extends: []string{"unknown"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		filter  string
+		want    *FilterConfiguration
+		wantErr string
+	}{
+		{
+			name:   "extends base filter",
+			filter: "extended",
+			want: &FilterConfiguration{
+				Extends:    []string{"base"},
+				Macros:     map[string]string{"Old": "SeedingDays > 60", "Small": "TotalBytes < 1"},
+				Safeguards: SafeguardsConfiguration{MaxRemoved: 10, MaxRemovedPercent: 5},
+				Ignore:     []string{"IsTrackerDown()"},
+				Remove:     []string{"IsUnregistered()", "Old"},
+				Label: []FilterLabelConfiguration{
+					{Name: "permaseed", Update: []string{"Seeds < 5"}},
+					{Name: "archive", Update: []string{"Old"}},
+				},
+			},
+		},
+		{
+			name:   "overrides sections",
+			filter: "overridden",
+			want: &FilterConfiguration{
+				Extends:    []string{"base"},
+				Override:   []string{"remove", "safeguards", "label"},
+				Macros:     map[string]string{"Old": "SeedingDays > 30"},
+				Safeguards: SafeguardsConfiguration{MaxRemoved: 50},
+				Ignore:     []string{"IsTrackerDown()"},
+				Remove:     []string{"Ratio > 4"},
+			},
+		},
+		{
+			name:   "extends filters in order",
+			filter: "multiple",
+			want: &FilterConfiguration{
+				Extends:    []string{"extended", "overridden"},
+				Macros:     map[string]string{"Old": "SeedingDays > 30", "Small": "TotalBytes < 1"},
+				Safeguards: SafeguardsConfiguration{MaxRemoved: 50},
+				Ignore:     []string{"IsTrackerDown()", "IsTrackerDown()"},
+				Remove:     []string{"Ratio > 4"},
+			},
+		},
+		{
+			name:    "detects self extension",
+			filter:  "self",
+			wantErr: "extends cycle: self -> self",
+		},
+		{
+			name:    "detects extends cycle",
+			filter:  "loop-a",
+			wantErr: "extends cycle: loop-a -> loop-b -> loop-a",
+		},
+		{
+			name:    "reports unknown filter",
+			filter:  "missing",
+			wantErr: "failed finding configuration of filter: unknown",
+		},
+	}
+
+	Config = &Configuration{Filters: filters}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetFilter(tt.filter)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("GetFilter() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetFilter() error = %v", err)
+			}
+
+			// extends are not merged
+			got.Extends = tt.want.Extends
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetFilter() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeFilters(t *testing.T) {
+	Config = &Configuration{Filters: map[string]FilterConfiguration{
+		"first": {
+			Remove: []string{"IsUnregistered()"},
+			Label:  []FilterLabelConfiguration{{Name: "keep", Update: []string{"Seeds < 3"}}},
+		},
+		"second": {
+			Override: []string{"label"},
+			Remove:   []string{"Ratio > 4"},
+		},
+	}}
+
+	got, err := MergeFilters("first", "second")
+	if err != nil {
+		t.Fatalf("MergeFilters() error = %v", err)
+	}
+
+	if want := []string{"IsUnregistered()", "Ratio > 4"}; !reflect.DeepEqual(got.Remove, want) {
+		t.Errorf("MergeFilters() remove = %v, want %v", got.Remove, want)
+	}
+	if len(got.Label) != 0 {
+		t.Errorf("MergeFilters() label = %v, want none", got.Label)
+	}
+
+	// merging must not modify the configured filters
+	if first := Config.Filters["first"]; len(first.Remove) != 1 || len(first.Label) != 1 {
+		t.Errorf("MergeFilters() modified filter: %+v", first)
+	}
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"strings"
+	"time"
+
+	"github.com/l3uddz/tqm/state"
+)
+
+type UnregisteredGraceConfiguration struct {
+	Runs     int           `validate:"min=0"`
+	Duration time.Duration `validate:"duration"`
+	Trackers []UnregisteredGraceOverride
+}
+
+type UnregisteredGraceOverride struct {
+	Trackers []string      `validate:"required"`
+	Runs     int           `validate:"min=0"`
+	Duration time.Duration `validate:"duration"`
+}
+
+/* Public */
+
+// ApplyUnregisteredGrace tracks the torrents seen unregistered by consecutive runs in the client state, torrents are
+// only unregistered once they have been seen for the configured runs or duration of their tracker.
+func ApplyUnregisteredGrace(torrents map[string]Torrent, cs *state.ClientState, now time.Time) {
+	cs.UnregisteredTorrents = evaluateUnregisteredGrace(torrents, cs, now, 1)
+}
+
+// CheckUnregisteredGrace applies the grace tracked by previous runs without counting this run or changing the client
+// state, for runs which do not persist the state (e.g. relabel)
+func CheckUnregisteredGrace(torrents map[string]Torrent, cs *state.ClientState, now time.Time) {
+	evaluateUnregisteredGrace(torrents, cs, now, 0)
+}
+
+/* Private */
+
+// evaluateUnregisteredGrace applies the grace of the unregistered torrents, counting this run when runs is set, and
+// returns the unregistered torrents to track
+func evaluateUnregisteredGrace(torrents map[string]Torrent, cs *state.ClientState, now time.Time,
+	runs int) map[string]*state.UnregisteredTorrent {
+	seen := make(map[string]*state.UnregisteredTorrent)
+
+	for h, t := range torrents {
+		if !t.isUnregistered() {
+			continue
+		}
+
+		// track unregistered torrents across runs (copied, so the client state is only changed by the caller)
+		ut := state.UnregisteredTorrent{Since: now}
+		if prev, ok := cs.UnregisteredTorrents[t.Hash]; ok {
+			ut = *prev
+		}
+		ut.Runs += runs
+		seen[t.Hash] = &ut
+
+		graceRuns, graceDuration := unregisteredGrace(t.TrackerName)
+		elapsed := now.Sub(ut.Since)
+
+		t.UnregisteredSince = ut.Since.Unix()
+		t.UnregisteredHours = float32(elapsed.Hours())
+		t.unregisteredGrace = !unregisteredGracePassed(ut.Runs, elapsed, graceRuns, graceDuration)
+		torrents[h] = t
+	}
+
+	// torrents no longer unregistered (or removed) start over
+	return seen
+}
+
+func unregisteredGrace(trackerName string) (int, time.Duration) {
+	cfg := Config.UnregisteredGrace
+	for _, o := range cfg.Trackers {
+		for _, t := range o.Trackers {
+			if strings.EqualFold(t, trackerName) {
+				return o.Runs, o.Duration
+			}
+		}
+	}
+
+	return cfg.Runs, cfg.Duration
+}
+
+// unregisteredGracePassed returns whether either of the configured grace thresholds was reached
+func unregisteredGracePassed(seenRuns int, elapsed time.Duration, runs int, duration time.Duration) bool {
+	switch {
+	case runs == 0 && duration == 0:
+		return true
+	case runs > 0 && seenRuns >= runs:
+		return true
+	case duration > 0 && elapsed >= duration:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/l3uddz/tqm/state"
+)
+
+func TestUnregisteredGrace(t *testing.T) {
+	if err := loadTrackerErrors(TrackerErrorsConfiguration{}); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	type run struct {
+		// relabel runs only check the grace
+		check bool
+		after time.Duration
+		want  bool
+	}
+
+	tests := []struct {
+		name  string
+		grace UnregisteredGraceConfiguration
+		runs  []run
+	}{
+		{
+			name:  "no grace",
+			grace: UnregisteredGraceConfiguration{},
+			runs:  []run{{check: true, want: true}, {want: true}},
+		},
+		{
+			name:  "runs",
+			grace: UnregisteredGraceConfiguration{Runs: 2},
+			runs: []run{
+				{want: false},
+				{check: true, want: false},
+				{check: true, want: false},
+				{want: true},
+				{check: true, want: true},
+			},
+		},
+		{
+			name:  "relabel before clean",
+			grace: UnregisteredGraceConfiguration{Runs: 1},
+			runs:  []run{{check: true, want: false}, {want: true}, {check: true, want: true}},
+		},
+		{
+			name:  "duration",
+			grace: UnregisteredGraceConfiguration{Duration: time.Hour},
+			runs: []run{
+				{want: false},
+				{check: true, after: 30 * time.Minute, want: false},
+				{check: true, after: time.Hour, want: true},
+				{after: time.Hour, want: true},
+			},
+		},
+		{
+			name: "tracker override",
+			grace: UnregisteredGraceConfiguration{Runs: 5, Trackers: []UnregisteredGraceOverride{
+				{Trackers: []string{"TR"}, Runs: 1},
+			}},
+			runs: []run{{want: true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Config = &Configuration{UnregisteredGrace: tt.grace}
+			cs := new(state.ClientState)
+
+			for i, r := range tt.runs {
+				torrents := map[string]Torrent{
+					"abc": {Hash: "abc", TrackerName: "tr", TrackerStatus: "Unregistered torrent"},
+				}
+
+				before := trackedRuns(cs, "abc")
+				if r.check {
+					CheckUnregisteredGrace(torrents, cs, start.Add(r.after))
+
+					// the client state is not changed by checks
+					if after := trackedRuns(cs, "abc"); after != before {
+						t.Errorf("run %d: check changed the runs from %d to %d", i, before, after)
+					}
+				} else {
+					ApplyUnregisteredGrace(torrents, cs, start.Add(r.after))
+				}
+
+				tr := torrents["abc"]
+				if got := tr.IsUnregistered(); got != r.want {
+					t.Errorf("run %d: IsUnregistered() = %v, want %v", i, got, r.want)
+				}
+			}
+		})
+	}
+}
+
+func trackedRuns(cs *state.ClientState, hash string) int {
+	if ut, ok := cs.UnregisteredTorrents[hash]; ok {
+		return ut.Runs
+	}
+	return 0
+}
+
+func TestUnregisteredGraceStartsOver(t *testing.T) {
+	if err := loadTrackerErrors(TrackerErrorsConfiguration{}); err != nil {
+		t.Fatal(err)
+	}
+
+	Config = &Configuration{UnregisteredGrace: UnregisteredGraceConfiguration{Runs: 2}}
+	cs := new(state.ClientState)
+	now := time.Now()
+
+	unregistered := map[string]Torrent{"abc": {Hash: "abc", TrackerStatus: "Unregistered torrent"}}
+	ApplyUnregisteredGrace(unregistered, cs, now)
+
+	// the torrent is no longer unregistered
+	ApplyUnregisteredGrace(map[string]Torrent{"abc": {Hash: "abc", TrackerStatus: "Working"}}, cs, now)
+	if len(cs.UnregisteredTorrents) != 0 {
+		t.Fatalf("unregistered torrents = %d, want 0", len(cs.UnregisteredTorrents))
+	}
+
+	unregistered = map[string]Torrent{"abc": {Hash: "abc", TrackerStatus: "Unregistered torrent"}}
+	ApplyUnregisteredGrace(unregistered, cs, now)
+	if tr := unregistered["abc"]; tr.IsUnregistered() {
+		t.Errorf("IsUnregistered() = true, want false")
+	}
+}
@@ -0,0 +1,185 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/knadh/koanf"
+	"github.com/knadh/koanf/parsers/json"
+	"github.com/knadh/koanf/parsers/toml"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+)
+
+const (
+	includeKey       = "include"
+	includeDirectory = "conf.d"
+)
+
+var (
+	// sections whose named entries must only be defined by a single file
+	includeSections = []string{
+		"clients",
+		"filters",
+		"trackers.unit3d",
+		"trackers.gazelle",
+	}
+)
+
+type configLoader struct {
+	target *koanf.Koanf
+	files  []string
+	owners map[string]string
+}
+
+/* Private */
+
+// loadFiles loads the configuration file, the files it includes and the files of the conf.d directory next to it
+// into k
+func loadFiles(k *koanf.Koanf, configFilePath string) ([]string, error) {
+	l := &configLoader{
+		target: k,
+		owners: make(map[string]string),
+	}
+
+	if err := l.load(configFilePath); err != nil {
+		return nil, err
+	}
+
+	// conf.d files are loaded in lexical order
+	dir := filepath.Join(filepath.Dir(configFilePath), includeDirectory)
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read %s: %w", dir, err)
+	}
+
+	for _, e := range entries {
+		if e.IsDir() || parserFor(e.Name()) == nil {
+			continue
+		}
+
+		if err := l.load(filepath.Join(dir, e.Name())); err != nil {
+			return nil, err
+		}
+	}
+
+	return l.files, nil
+}
+
+func (l *configLoader) load(path string) error {
+	// files are only loaded once
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	for _, f := range l.files {
+		if f == abs {
+			return nil
+		}
+	}
+	l.files = append(l.files, abs)
+
+	// files without a known extension are yaml
+	parser := parserFor(path)
+	if parser == nil {
+		parser = yaml.Parser()
+	}
+
+	k := koanf.New(Delimiter)
+	if err := k.Load(file.Provider(path), parser); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	// includes can be a single path or a list of paths
+	includes := k.Strings(includeKey)
+	if s, ok := k.Get(includeKey).(string); ok {
+		includes = []string{s}
+	}
+	k.Delete(includeKey)
+
+	// detect settings defined by more than one file
+	if err := l.own(abs, k); err != nil {
+		return err
+	}
+
+	if err := l.target.Merge(k); err != nil {
+		return fmt.Errorf("%s: merge: %w", path, err)
+	}
+
+	// load included files, relative to the including file
+	for _, include := range includes {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+
+		matches, err := filepath.Glob(include)
+		if err != nil {
+			return fmt.Errorf("%s: include %q: %w", path, include, err)
+		}
+
+		if len(matches) == 0 && !strings.ContainsAny(include, "*?[") {
+			return fmt.Errorf("%s: include %q: file not found", path, include)
+		}
+
+		sort.Strings(matches)
+		for _, m := range matches {
+			if err := l.load(m); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (l *configLoader) own(path string, k *koanf.Koanf) error {
+	keys := make([]string, 0)
+
+	// named entries of sections are owned as a whole
+	for _, section := range includeSections {
+		for _, name := range k.MapKeys(section) {
+			keys = append(keys, section+Delimiter+name)
+		}
+	}
+
+	for _, key := range k.Keys() {
+		owned := false
+		for _, section := range includeSections {
+			if strings.HasPrefix(key, section+Delimiter) {
+				owned = true
+				break
+			}
+		}
+
+		if !owned {
+			keys = append(keys, key)
+		}
+	}
+
+	for _, key := range keys {
+		if owner, ok := l.owners[key]; ok {
+			return fmt.Errorf("%s: defined in both %s and %s", key, owner, path)
+		}
+
+		l.owners[key] = path
+	}
+
+	return nil
+}
+
+func parserFor(path string) koanf.Parser {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return yaml.Parser()
+	case ".json":
+		return json.Parser()
+	case ".toml":
+		return toml.Parser()
+	default:
+		return nil
+	}
+}
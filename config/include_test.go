@@ -0,0 +1,136 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/knadh/koanf"
+)
+
+func TestLoadFiles(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string]string
+		wantFiles []string
+		want      map[string]interface{}
+		wantErr   string
+	}{
+		{
+			name: "includes and conf.d",
+			files: map[string]string{
+				"config.yml":         "include: [clients.yml, filters/*.yml]\ntrackers:\n  bhd:\n    api_key: key\n",
+				"clients.yml":        "clients:\n  qbt:\n    type: qbittorrent\n",
+				"filters/a.yml":      "filters:\n  a:\n    remove: [Seeds > 1]\n",
+				"filters/b.yml":      "filters:\n  b:\n    remove: [Seeds > 2]\n",
+				"conf.d/deluge.yaml": "clients:\n  deluge:\n    type: deluge\n",
+				"conf.d/notes.txt":   "ignored",
+			},
+			wantFiles: []string{"config.yml", "clients.yml", "filters/a.yml", "filters/b.yml", "conf.d/deluge.yaml"},
+			want: map[string]interface{}{
+				"trackers.bhd.api_key": "key",
+				"clients.qbt.type":     "qbittorrent",
+				"clients.deluge.type":  "deluge",
+				"filters.b.remove":     []interface{}{"Seeds > 2"},
+			},
+		},
+		{
+			name: "single include loaded once",
+			files: map[string]string{
+				"config.yml":  "include: clients.yml\n",
+				"clients.yml": "include: config.yml\nclients:\n  qbt:\n    type: qbittorrent\n",
+			},
+			wantFiles: []string{"config.yml", "clients.yml"},
+			want:      map[string]interface{}{"clients.qbt.type": "qbittorrent"},
+		},
+		{
+			name: "json and toml includes",
+			files: map[string]string{
+				"config.yml":   "include: [a.json, b.toml]\n",
+				"a.json":       `{"clients": {"qbt": {"type": "qbittorrent"}}}`,
+				"b.toml":       "[clients.deluge]\ntype = \"deluge\"\n",
+				"conf.d/c.yml": "unregistered_grace:\n  runs: 2\n",
+			},
+			wantFiles: []string{"config.yml", "a.json", "b.toml", "conf.d/c.yml"},
+			want: map[string]interface{}{
+				"clients.qbt.type":        "qbittorrent",
+				"clients.deluge.type":     "deluge",
+				"unregistered_grace.runs": 2,
+			},
+		},
+		{
+			name: "client defined twice",
+			files: map[string]string{
+				"config.yml":   "clients:\n  qbt:\n    type: qbittorrent\n",
+				"conf.d/a.yml": "clients:\n  qbt:\n    url: http://localhost\n",
+			},
+			wantErr: "clients.qbt: defined in both",
+		},
+		{
+			name: "setting defined twice",
+			files: map[string]string{
+				"config.yml": "include: a.yml\nunregistered_grace:\n  runs: 1\n",
+				"a.yml":      "unregistered_grace:\n  runs: 2\n",
+			},
+			wantErr: "unregistered_grace.runs: defined in both",
+		},
+		{
+			name: "missing include",
+			files: map[string]string{
+				"config.yml": "include: missing.yml\n",
+			},
+			wantErr: "file not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, data := range tt.files {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			prev := K
+			K = koanf.New(Delimiter)
+			t.Cleanup(func() {
+				K = prev
+			})
+
+			files, err := loadFiles(K, filepath.Join(dir, "config.yml"))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("loadFiles() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadFiles() error = %v", err)
+			}
+
+			wantFiles := make([]string, 0, len(tt.wantFiles))
+			for _, f := range tt.wantFiles {
+				wantFiles = append(wantFiles, filepath.Join(dir, f))
+			}
+			if !reflect.DeepEqual(files, wantFiles) {
+				t.Errorf("loadFiles() files = %v, want %v", files, wantFiles)
+			}
+
+			for path, v := range tt.want {
+				if got := K.Get(path); !reflect.DeepEqual(got, v) {
+					t.Errorf("loadFiles() %s = %#v, want %#v", path, got, v)
+				}
+			}
+			if K.Exists(includeKey) {
+				t.Errorf("loadFiles() kept %s: %v", includeKey, K.Get(includeKey))
+			}
+		})
+	}
+}
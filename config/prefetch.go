@@ -0,0 +1,39 @@
+package config
+
+import (
+	"context"
+
+	"github.com/l3uddz/tqm/tracker"
+)
+
+// SetLookupContext sets the context of the tracker api lookups made while evaluating the filters of the torrents
+func SetLookupContext(ctx context.Context, torrents map[string]Torrent) {
+	for h, t := range torrents {
+		t.lookupCtx = ctx
+		torrents[h] = t
+	}
+}
+
+// PrefetchTrackerData resolves tracker api lookups for the torrents before filters are evaluated
+func PrefetchTrackerData(ctx context.Context, torrents map[string]Torrent, unregistered bool, metadata bool) int {
+	requests := make([]tracker.PrefetchRequest, 0, len(torrents))
+	for _, t := range torrents {
+		t := t
+
+		// the tracker api is only checked when the status is not a known unregistered status
+		checkUnregistered := unregistered && t.TrackerStatus != "" &&
+			t.TrackerErrorCategory() != TrackerErrorUnregistered
+
+		if !checkUnregistered && !metadata {
+			continue
+		}
+
+		requests = append(requests, tracker.PrefetchRequest{
+			Torrent:      t.trackerTorrent(),
+			Unregistered: checkUnregistered,
+			Metadata:     metadata,
+		})
+	}
+
+	return tracker.Prefetch(ctx, requests)
+}
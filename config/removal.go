@@ -0,0 +1,105 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	RemovalStrategyNone         = "none"
+	RemovalStrategyReannounce   = "reannounce"
+	RemovalStrategyStopAnnounce = "stop_announce"
+)
+
+type RemovalConfiguration struct {
+	RemovalStrategy `koanf:",squash"`
+
+	BatchSize int `koanf:"batch_size" validate:"min=0"`
+	Trackers  []RemovalOverride
+}
+
+type RemovalOverride struct {
+	RemovalStrategy `koanf:",squash"`
+
+	Trackers []string `validate:"required"`
+}
+
+type RemovalStrategy struct {
+	Strategy        string        `validate:"oneof=none reannounce stop_announce"`
+	PauseDelay      time.Duration `koanf:"pause_delay" validate:"duration"`
+	ResumeDelay     time.Duration `koanf:"resume_delay" validate:"duration"`
+	ReannounceDelay time.Duration `koanf:"reannounce_delay" validate:"duration"`
+}
+
+var (
+	defaultRemovalStrategy = RemovalStrategy{
+		Strategy:        RemovalStrategyStopAnnounce,
+		PauseDelay:      1 * time.Second,
+		ResumeDelay:     2 * time.Second,
+		ReannounceDelay: 2 * time.Second,
+	}
+)
+
+/* Public */
+
+// ForTracker returns the removal strategy of the tracker, with the defaults applied to the settings not set
+func (r RemovalConfiguration) ForTracker(trackerName string) RemovalStrategy {
+	for _, o := range r.Trackers {
+		for _, t := range o.Trackers {
+			if strings.EqualFold(t, trackerName) {
+				return r.RemovalStrategy.merge(o.RemovalStrategy).withDefaults()
+			}
+		}
+	}
+
+	return r.RemovalStrategy.withDefaults()
+}
+
+// Batch returns the maximum torrents removed per call to the client
+func (r RemovalConfiguration) Batch() int {
+	if r.BatchSize < 1 {
+		return 1
+	}
+	return r.BatchSize
+}
+
+// Timeout returns the maximum duration of a removal with the strategy, when each request to the client takes at most
+// the request timeout
+func (s RemovalStrategy) Timeout(requestTimeout time.Duration) time.Duration {
+	switch s.Strategy {
+	case RemovalStrategyReannounce:
+		// re-announce and remove
+		return 2*requestTimeout + s.ReannounceDelay
+	case RemovalStrategyStopAnnounce:
+		// pause, resume, re-announce and remove
+		return 4*requestTimeout + s.PauseDelay + s.ResumeDelay + s.ReannounceDelay
+	default:
+		return requestTimeout
+	}
+}
+
+/* Private */
+
+// merge returns the strategy with the settings set by the other strategy replaced
+func (s RemovalStrategy) merge(o RemovalStrategy) RemovalStrategy {
+	if o.Strategy != "" {
+		s.Strategy = o.Strategy
+	}
+	if o.PauseDelay != 0 {
+		s.PauseDelay = o.PauseDelay
+	}
+	if o.ResumeDelay != 0 {
+		s.ResumeDelay = o.ResumeDelay
+	}
+	if o.ReannounceDelay != 0 {
+		s.ReannounceDelay = o.ReannounceDelay
+	}
+
+	return s
+}
+
+func (s RemovalStrategy) withDefaults() RemovalStrategy {
+	s = defaultRemovalStrategy.merge(s)
+	s.Strategy = strings.ToLower(s.Strategy)
+	return s
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRemovalConfigurationForTracker(t *testing.T) {
+	cfg := RemovalConfiguration{
+		RemovalStrategy: RemovalStrategy{Strategy: "Reannounce", ReannounceDelay: 5 * time.Second},
+		Trackers: []RemovalOverride{
+			{Trackers: []string{"BHD"}, RemovalStrategy: RemovalStrategy{Strategy: "none"}},
+			{Trackers: []string{"PTP"}, RemovalStrategy: RemovalStrategy{PauseDelay: 3 * time.Second}},
+		},
+	}
+
+	tests := []struct {
+		tracker string
+		want    RemovalStrategy
+	}{
+		{tracker: "other", want: RemovalStrategy{Strategy: "reannounce", PauseDelay: time.Second,
+			ResumeDelay: 2 * time.Second, ReannounceDelay: 5 * time.Second}},
+		{tracker: "bhd", want: RemovalStrategy{Strategy: "none", PauseDelay: time.Second,
+			ResumeDelay: 2 * time.Second, ReannounceDelay: 5 * time.Second}},
+		{tracker: "PTP", want: RemovalStrategy{Strategy: "reannounce", PauseDelay: 3 * time.Second,
+			ResumeDelay: 2 * time.Second, ReannounceDelay: 5 * time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tracker, func(t *testing.T) {
+			if got := cfg.ForTracker(tt.tracker); got != tt.want {
+				t.Errorf("ForTracker(%q) = %+v, want %+v", tt.tracker, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemovalConfigurationBatch(t *testing.T) {
+	tests := []struct {
+		size int
+		want int
+	}{
+		{size: 0, want: 1},
+		{size: 1, want: 1},
+		{size: 20, want: 20},
+	}
+
+	for _, tt := range tests {
+		if got := (RemovalConfiguration{BatchSize: tt.size}).Batch(); got != tt.want {
+			t.Errorf("Batch() with batch_size %d = %d, want %d", tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestRemovalStrategyTimeout(t *testing.T) {
+	tests := []struct {
+		strategy RemovalStrategy
+		want     time.Duration
+	}{
+		{
+			strategy: RemovalStrategy{Strategy: RemovalStrategyNone},
+			want:     time.Minute,
+		},
+		{
+			strategy: RemovalStrategy{Strategy: RemovalStrategyReannounce, ReannounceDelay: 3 * time.Second},
+			want:     2*time.Minute + 3*time.Second,
+		},
+		{
+			strategy: RemovalStrategy{Strategy: RemovalStrategyStopAnnounce, PauseDelay: time.Second,
+				ResumeDelay: 2 * time.Second, ReannounceDelay: 3 * time.Second},
+			want: 4*time.Minute + 6*time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tt.strategy.Timeout(time.Minute); got != tt.want {
+			t.Errorf("Timeout() of %s = %s, want %s", tt.strategy.Strategy, got, tt.want)
+		}
+	}
+}
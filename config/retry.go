@@ -0,0 +1,48 @@
+package config
+
+import (
+	"time"
+)
+
+type RetryConfiguration struct {
+	Attempts   int           `validate:"min=0"`
+	Backoff    time.Duration `validate:"duration"`
+	MaxBackoff time.Duration `koanf:"max_backoff" validate:"duration"`
+}
+
+var (
+	defaultRetryAttempts   = 3
+	defaultRetryBackoff    = 2 * time.Second
+	defaultRetryMaxBackoff = 30 * time.Second
+)
+
+/* Public */
+
+// MaxAttempts returns the attempts made for an operation, including the first one
+func (r RetryConfiguration) MaxAttempts() int {
+	if r.Attempts == 0 {
+		return defaultRetryAttempts
+	}
+	return r.Attempts
+}
+
+// Delay returns the exponential backoff before the next attempt, following the failed attempt (starting at 1)
+func (r RetryConfiguration) Delay(attempt int) time.Duration {
+	backoff, maxBackoff := r.Backoff, r.MaxBackoff
+	if backoff == 0 {
+		backoff = defaultRetryBackoff
+	}
+	if maxBackoff == 0 {
+		maxBackoff = defaultRetryMaxBackoff
+	}
+
+	delay := backoff
+	for i := 1; i < attempt && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > maxBackoff {
+		return maxBackoff
+	}
+	return delay
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryConfiguration(t *testing.T) {
+	tests := []struct {
+		name         string
+		retry        RetryConfiguration
+		wantAttempts int
+		wantDelays   []time.Duration
+	}{
+		{
+			name:         "defaults",
+			wantAttempts: 3,
+			wantDelays:   []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, 30 * time.Second, 30 * time.Second},
+		},
+		{
+			name:         "configured",
+			retry:        RetryConfiguration{Attempts: 5, Backoff: time.Second, MaxBackoff: 5 * time.Second},
+			wantAttempts: 5,
+			wantDelays:   []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second},
+		},
+		{
+			name:         "single attempt",
+			retry:        RetryConfiguration{Attempts: 1},
+			wantAttempts: 1,
+			wantDelays:   []time.Duration{2 * time.Second},
+		},
+		{
+			name:         "backoff above max backoff",
+			retry:        RetryConfiguration{Backoff: time.Minute, MaxBackoff: 10 * time.Second},
+			wantAttempts: 3,
+			wantDelays:   []time.Duration{10 * time.Second, 10 * time.Second},
+		},
+		{
+			name:         "many attempts",
+			retry:        RetryConfiguration{Backoff: time.Second, MaxBackoff: time.Hour},
+			wantAttempts: 3,
+			wantDelays:   []time.Duration{time.Second, 2 * time.Second, 4 * time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.retry.MaxAttempts(); got != tt.wantAttempts {
+				t.Errorf("MaxAttempts() = %d, want %d", got, tt.wantAttempts)
+			}
+
+			for i, want := range tt.wantDelays {
+				if got := tt.retry.Delay(i + 1); got != want {
+					t.Errorf("Delay(%d) = %s, want %s", i+1, got, want)
+				}
+			}
+		})
+	}
+
+	// large attempts do not overflow
+	if got := (RetryConfiguration{}).Delay(1000); got != defaultRetryMaxBackoff {
+		t.Errorf("Delay(1000) = %s, want %s", got, defaultRetryMaxBackoff)
+	}
+}
@@ -0,0 +1,37 @@
+package config
+
+type SafeguardsConfiguration struct {
+	MaxRemoved              int     `koanf:"max_removed" yaml:"max_removed,omitempty" validate:"min=0"`
+	MaxRemovedPercent       float64 `koanf:"max_removed_percent" yaml:"max_removed_percent,omitempty" validate:"min=0,max=100"`
+	MaxHardRemovedGB        float64 `koanf:"max_hard_removed_gb" yaml:"max_hard_removed_gb,omitempty" validate:"min=0"`
+	MaxUnregisteredIncrease int     `koanf:"max_unregistered_increase" yaml:"max_unregistered_increase,omitempty" validate:"min=0"`
+	MaxTrackerErrorPercent  float64 `koanf:"max_tracker_error_percent" yaml:"max_tracker_error_percent,omitempty" validate:"min=0,max=100"`
+	MinTrackerTorrents      int     `koanf:"min_tracker_torrents" yaml:"min_tracker_torrents,omitempty" validate:"min=0"`
+}
+
+/* Public */
+
+// Stricter returns the stricter limit of both configurations for each safeguard, unset limits are ignored
+func (s SafeguardsConfiguration) Stricter(o SafeguardsConfiguration) SafeguardsConfiguration {
+	return SafeguardsConfiguration{
+		MaxRemoved:              stricterLimit(s.MaxRemoved, o.MaxRemoved),
+		MaxRemovedPercent:       stricterLimit(s.MaxRemovedPercent, o.MaxRemovedPercent),
+		MaxHardRemovedGB:        stricterLimit(s.MaxHardRemovedGB, o.MaxHardRemovedGB),
+		MaxUnregisteredIncrease: stricterLimit(s.MaxUnregisteredIncrease, o.MaxUnregisteredIncrease),
+		MaxTrackerErrorPercent:  stricterLimit(s.MaxTrackerErrorPercent, o.MaxTrackerErrorPercent),
+		MinTrackerTorrents:      stricterLimit(s.MinTrackerTorrents, o.MinTrackerTorrents),
+	}
+}
+
+/* Private */
+
+func stricterLimit[T int | float64](a T, b T) T {
+	switch {
+	case a == 0:
+		return b
+	case b == 0 || a < b:
+		return a
+	default:
+		return b
+	}
+}
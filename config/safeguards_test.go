@@ -0,0 +1,40 @@
+package config
+
+import "testing"
+
+func TestSafeguardsStricter(t *testing.T) {
+	tests := []struct {
+		name string
+		s    SafeguardsConfiguration
+		o    SafeguardsConfiguration
+		want SafeguardsConfiguration
+	}{
+		{
+			name: "unset limits are ignored",
+			s:    SafeguardsConfiguration{MaxRemoved: 10},
+			o:    SafeguardsConfiguration{MaxRemovedPercent: 5},
+			want: SafeguardsConfiguration{MaxRemoved: 10, MaxRemovedPercent: 5},
+		},
+		{
+			name: "lower limits win",
+			s:    SafeguardsConfiguration{MaxRemoved: 10, MaxHardRemovedGB: 50, MaxTrackerErrorPercent: 20},
+			o:    SafeguardsConfiguration{MaxRemoved: 20, MaxHardRemovedGB: 25.5, MaxTrackerErrorPercent: 30},
+			want: SafeguardsConfiguration{MaxRemoved: 10, MaxHardRemovedGB: 25.5, MaxTrackerErrorPercent: 20},
+		},
+		{
+			name: "both unset",
+			want: SafeguardsConfiguration{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.Stricter(tt.o); got != tt.want {
+				t.Errorf("Stricter() = %+v, want %+v", got, tt.want)
+			}
+			if got := tt.o.Stricter(tt.s); got != tt.want {
+				t.Errorf("Stricter() reversed = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
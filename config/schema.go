@@ -0,0 +1,187 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	schemaDraft = "http://json-schema.org/draft-07/schema#"
+)
+
+/* Public */
+
+// Schema returns a JSON Schema of the configuration file, which editors can use for validation and completion
+func Schema() map[string]interface{} {
+	s := schemaOf(reflect.TypeOf(Configuration{}))
+	s["$schema"] = schemaDraft
+	s["title"] = "tqm configuration"
+
+	// clients are validated against the schema of their type
+	types := make([]string, 0, len(clientConfigurations))
+	for t := range clientConfigurations {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	clients := make([]interface{}, 0, len(types))
+	for _, t := range types {
+		cs := schemaOf(reflect.TypeOf(clientConfigurations[t]()))
+		cs["properties"].(map[string]interface{})["type"] = map[string]interface{}{
+			"const": t,
+		}
+		clients = append(clients, cs)
+	}
+
+	s["properties"].(map[string]interface{})["clients"] = map[string]interface{}{
+		"type": "object",
+		"additionalProperties": map[string]interface{}{
+			"oneOf": clients,
+		},
+	}
+
+	return s
+}
+
+/* Private */
+
+func schemaOf(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// durations are decoded from strings such as 1h30m
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return map[string]interface{}{
+			"type":    "string",
+			"pattern": `^(\d+(\.\d+)?(ns|us|µs|ms|s|m|h))+$`,
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Map:
+		values := schemaOf(t.Elem())
+
+		// numeric values are decoded as strings, e.g. numeric macros
+		if t.Elem().Kind() == reflect.String {
+			values["type"] = []string{"string", "number"}
+		}
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": values,
+		}
+	case reflect.Slice:
+		items := schemaOf(t.Elem())
+		array := map[string]interface{}{
+			"type":  "array",
+			"items": items,
+		}
+
+		// single values are accepted in place of a list
+		if t.Elem().Kind() == reflect.String {
+			return map[string]interface{}{
+				"anyOf": []interface{}{items, array},
+			}
+		}
+		return array
+	case reflect.Struct:
+		properties := make(map[string]interface{})
+		required := make([]string, 0)
+		addStructProperties(t, properties, &required)
+
+		s := map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+			// settings can be read from files via their _file variant
+			"patternProperties": map[string]interface{}{
+				secretFileSuffix + "$": map[string]interface{}{"type": "string"},
+			},
+			"additionalProperties": false,
+		}
+		if len(required) > 0 {
+			// required settings can also be set via their _file variant
+			all := make([]interface{}, 0, len(required))
+			for _, name := range required {
+				all = append(all, map[string]interface{}{
+					"anyOf": []interface{}{
+						map[string]interface{}{"required": []string{name}},
+						map[string]interface{}{"required": []string{name + secretFileSuffix}},
+					},
+				})
+			}
+			s["allOf"] = all
+		}
+		return s
+	}
+
+	return map[string]interface{}{}
+}
+
+func addStructProperties(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		// squashed structs share the properties of their parent
+		name, squash := settingName(field)
+		if squash {
+			addStructProperties(field.Type, properties, required)
+			continue
+		}
+
+		s := schemaOf(field.Type)
+		for _, rule := range strings.Split(field.Tag.Get(tagName), ",") {
+			applySchemaRule(field.Type, s, rule)
+			if rule == "required" {
+				*required = append(*required, name)
+			}
+		}
+
+		properties[name] = s
+	}
+}
+
+func applySchemaRule(t reflect.Type, s map[string]interface{}, rule string) {
+	name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+	switch name {
+	case "url":
+		s["format"] = "uri"
+	case "oneof":
+		values := make([]interface{}, 0)
+		for _, v := range strings.Fields(arg) {
+			values = append(values, v)
+		}
+		s["enum"] = values
+	case "min", "max":
+		n, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return
+		}
+
+		keyword := map[string]string{"min": "minimum", "max": "maximum"}[name]
+		switch t.Kind() {
+		case reflect.String:
+			keyword = map[string]string{"min": "minLength", "max": "maxLength"}[name]
+		case reflect.Slice:
+			keyword = map[string]string{"min": "minItems", "max": "maxItems"}[name]
+		case reflect.Map:
+			keyword = map[string]string{"min": "minProperties", "max": "maxProperties"}[name]
+		}
+		s[keyword] = n
+	}
+}
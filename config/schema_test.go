@@ -0,0 +1,97 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	s := Schema()
+	if _, err := json.Marshal(s); err != nil {
+		t.Fatalf("Schema() is not json: %v", err)
+	}
+
+	// property returns the schema of the nested property
+	property := func(s map[string]interface{}, names ...string) map[string]interface{} {
+		for _, name := range names {
+			p, ok := s["properties"].(map[string]interface{})[name].(map[string]interface{})
+			if !ok {
+				t.Fatalf("Schema() has no property %q in %v", name, s)
+			}
+			s = p
+		}
+		return s
+	}
+
+	clients := property(s, "clients")["additionalProperties"].(map[string]interface{})["oneOf"].([]interface{})
+	if len(clients) != 2 {
+		t.Fatalf("Schema() clients = %d types, want 2", len(clients))
+	}
+	deluge, qbt := clients[0].(map[string]interface{}), clients[1].(map[string]interface{})
+
+	tests := []struct {
+		name string
+		got  map[string]interface{}
+		want map[string]interface{}
+	}{
+		{
+			name: "client type",
+			got:  property(deluge, "type"),
+			want: map[string]interface{}{"const": "deluge"},
+		},
+		{
+			name: "bounds",
+			got:  property(deluge, "port"),
+			want: map[string]interface{}{"type": "integer", "minimum": 1.0, "maximum": 65535.0},
+		},
+		{
+			name: "url",
+			got:  property(qbt, "url"),
+			want: map[string]interface{}{"type": "string", "format": "uri"},
+		},
+		{
+			name: "bounds of float",
+			got:  property(qbt, "safeguards", "max_removed_percent"),
+			want: map[string]interface{}{"type": "number", "minimum": 0.0, "maximum": 100.0},
+		},
+		{
+			name: "duration",
+			got:  property(qbt, "connect_timeout"),
+			want: map[string]interface{}{"type": "string", "pattern": `^(\d+(\.\d+)?(ns|us|µs|ms|s|m|h))+$`},
+		},
+		{
+			name: "list of strings",
+			got:  property(qbt, "filter"),
+			want: map[string]interface{}{
+				"anyOf": []interface{}{
+					map[string]interface{}{"type": "string"},
+					map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("Schema() = %#v, want %#v", tt.got, tt.want)
+			}
+		})
+	}
+
+	// required settings can be set via their _file variant
+	want := map[string]interface{}{
+		"anyOf": []interface{}{
+			map[string]interface{}{"required": []string{"password"}},
+			map[string]interface{}{"required": []string{"password_file"}},
+		},
+	}
+	found := false
+	for _, r := range deluge["allOf"].([]interface{}) {
+		found = found || reflect.DeepEqual(r, want)
+	}
+	if !found {
+		t.Errorf("Schema() deluge required = %v, want password or password_file", deluge["allOf"])
+	}
+}
@@ -1,20 +1,10 @@
 package config
 
 import (
-	"github.com/l3uddz/tqm/tracker"
-	"strings"
-)
+	"context"
+	"errors"
 
-var (
-	unregisteredStatuses = []string{
-		"not registered with this tracker",
-		"torrent is not authorized for use on this tracker",
-		"torrent is not found",
-		"torrent not found",
-		"torrent has been nuked",
-		"torrent does not exist",
-		"unregistered torrent",
-	}
+	"github.com/l3uddz/tqm/tracker"
 )
 
 type Torrent struct {
@@ -45,41 +35,177 @@ type Torrent struct {
 
 	// tracker
 	TrackerName   string `json:"TrackerName"`
+	TrackerHost   string `json:"TrackerHost"`
 	TrackerStatus string `json:"TrackerStatus"`
+
+	// set by ApplyUnregisteredGrace
+	UnregisteredSince int64   `json:"UnregisteredSince"`
+	UnregisteredHours float32 `json:"UnregisteredHours"`
+	unregisteredGrace bool
+
+	// set by SetLookupContext
+	lookupCtx context.Context
 }
 
+var (
+	errNoTrackerApi    = errors.New("no tracker api configured")
+	errTorrentNotFound = errors.New("torrent not found on tracker")
+)
+
 func (t *Torrent) IsUnregistered() bool {
+	// torrents are not unregistered until their grace has passed
+	if t.unregisteredGrace {
+		return false
+	}
+
+	return t.isUnregistered()
+}
+
+func (t *Torrent) isUnregistered() bool {
 	if t.TrackerStatus == "" {
 		return false
 	}
 
-	// check hardcoded unregistered statuses
-	status := strings.ToLower(t.TrackerStatus)
-	for _, v := range unregisteredStatuses {
-		// unregistered tracker status found?
-		if strings.Contains(status, v) {
-			return true
-		}
+	// check configured unregistered statuses
+	if t.TrackerErrorCategory() == TrackerErrorUnregistered {
+		return true
 	}
 
 	// check tracker api (if available)
-	if tr := tracker.Get(t.TrackerName); tr != nil {
-		tt := &tracker.Torrent{
-			Hash:            t.Hash,
-			Name:            t.Name,
-			TotalBytes:      t.TotalBytes,
-			DownloadedBytes: t.DownloadedBytes,
-			State:           t.State,
-			Downloaded:      t.Downloaded,
-			Seeding:         t.Seeding,
-			TrackerName:     t.TrackerName,
-			TrackerStatus:   t.State,
-		}
-
-		if err, ur := tr.IsUnregistered(tt); err == nil {
+	if tr := tracker.Get(t.TrackerName, t.TrackerHost); tr != nil {
+		if err, ur := tr.IsUnregistered(t.lookupContext(), t.trackerTorrent()); err == nil {
 			return ur
 		}
 	}
 
 	return false
 }
+
+func (t *Torrent) IsTrackerDown() bool {
+	return t.TrackerErrorCategory() == TrackerErrorDown
+}
+
+func (t *Torrent) TrackerErrorCategory() string {
+	return GetTrackerErrorCategory(t.TrackerName, t.TrackerHost, t.TrackerStatus)
+}
+
+func (t *Torrent) TrackerSeeders() int64 {
+	if md, err := t.trackerMetadata(); err == nil {
+		return md.Seeders
+	}
+
+	return 0
+}
+
+func (t *Torrent) TrackerSnatched() int64 {
+	if md, err := t.trackerMetadata(); err == nil {
+		return md.Snatched
+	}
+
+	return 0
+}
+
+func (t *Torrent) IsFreeleech() bool {
+	if md, err := t.trackerMetadata(); err == nil {
+		return md.Freeleech
+	}
+
+	return false
+}
+
+func (t *Torrent) TrackerMinSeedHours() float64 {
+	// configured per tracker, so it does not depend on an api lookup
+	if tr, ok := tracker.Get(t.TrackerName, t.TrackerHost).(tracker.SeedTimeInterface); ok {
+		return tr.MinSeedHours()
+	}
+
+	return 0
+}
+
+func (t *Torrent) HasTrackerPolicy() bool {
+	return tracker.GetPolicy(t.TrackerName) != nil
+}
+
+func (t *Torrent) TrackerPolicy() tracker.Policy {
+	if p := tracker.GetPolicy(t.TrackerName); p != nil {
+		return *p
+	}
+
+	return tracker.Policy{}
+}
+
+func (t *Torrent) MeetsTrackerPolicy() bool {
+	p := tracker.GetPolicy(t.TrackerName)
+	if p == nil {
+		return false
+	}
+
+	// keep freeleech torrents (and torrents whose freeleech status could not be looked up)
+	if p.IgnoreIfFreeleech {
+		if md, err := t.trackerMetadata(); err != nil || md.Freeleech {
+			return false
+		}
+	}
+
+	// seeded for the maximum time
+	if p.MaxSeedDays > 0 && t.SeedingDays >= p.MaxSeedDays {
+		return true
+	}
+
+	// hit-and-run requirements must be satisfied
+	if p.HnRDays > 0 && t.SeedingDays < p.HnRDays {
+		return false
+	}
+	if hours := t.TrackerMinSeedHours(); hours > 0 && float64(t.SeedingHours) < hours {
+		return false
+	}
+
+	// configured minimum ratio or seed time (a policy without thresholds is never met)
+	return (p.MinRatio > 0 && t.Ratio >= p.MinRatio) || (p.MinSeedDays > 0 && t.SeedingDays >= p.MinSeedDays)
+}
+
+// trackerMetadata returns the metadata of the torrent from the tracker api, or an error when it could not be looked up
+func (t *Torrent) trackerMetadata() (*tracker.Metadata, error) {
+	tr := tracker.Get(t.TrackerName, t.TrackerHost)
+	if tr == nil {
+		return nil, errNoTrackerApi
+	}
+
+	mi, ok := tr.(tracker.MetadataInterface)
+	if !ok {
+		return nil, tracker.ErrMetadataUnsupported
+	}
+
+	md, err := mi.GetMetadata(t.lookupContext(), t.trackerTorrent())
+	if err != nil {
+		return nil, err
+	} else if md == nil {
+		return nil, errTorrentNotFound
+	}
+
+	return md, nil
+}
+
+// lookupContext returns the context of the tracker api lookups of the torrent
+func (t *Torrent) lookupContext() context.Context {
+	if t.lookupCtx == nil {
+		return context.Background()
+	}
+
+	return t.lookupCtx
+}
+
+func (t *Torrent) trackerTorrent() *tracker.Torrent {
+	return &tracker.Torrent{
+		Hash:            t.Hash,
+		Name:            t.Name,
+		TotalBytes:      t.TotalBytes,
+		DownloadedBytes: t.DownloadedBytes,
+		State:           t.State,
+		Downloaded:      t.Downloaded,
+		Seeding:         t.Seeding,
+		TrackerName:     t.TrackerName,
+		TrackerHost:     t.TrackerHost,
+		TrackerStatus:   t.TrackerStatus,
+	}
+}
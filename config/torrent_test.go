@@ -0,0 +1,216 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/l3uddz/tqm/tracker"
+)
+
+func TestMeetsTrackerPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  tracker.Policy
+		torrent Torrent
+		want    bool
+	}{
+		{
+			name:    "no policy",
+			policy:  tracker.Policy{Trackers: []string{"other"}, MinRatio: 1},
+			torrent: Torrent{Ratio: 5, SeedingDays: 50},
+			want:    false,
+		},
+		{
+			name:    "no thresholds",
+			policy:  tracker.Policy{Trackers: []string{"tr"}},
+			torrent: Torrent{Ratio: 5, SeedingDays: 50},
+			want:    false,
+		},
+		{
+			name:    "max seed days only, not reached",
+			policy:  tracker.Policy{Trackers: []string{"tr"}, MaxSeedDays: 30},
+			torrent: Torrent{Ratio: 5, SeedingDays: 1},
+			want:    false,
+		},
+		{
+			name:    "max seed days only, reached",
+			policy:  tracker.Policy{Trackers: []string{"tr"}, MaxSeedDays: 30},
+			torrent: Torrent{SeedingDays: 30},
+			want:    true,
+		},
+		{
+			name:    "hnr days only",
+			policy:  tracker.Policy{Trackers: []string{"tr"}, HnRDays: 5},
+			torrent: Torrent{Ratio: 5, SeedingDays: 10},
+			want:    false,
+		},
+		{
+			name:    "ignore if freeleech only",
+			policy:  tracker.Policy{Trackers: []string{"tr"}, IgnoreIfFreeleech: true},
+			torrent: Torrent{Ratio: 5, SeedingDays: 10},
+			want:    false,
+		},
+		{
+			name:    "min ratio reached",
+			policy:  tracker.Policy{Trackers: []string{"tr"}, MinRatio: 1, MinSeedDays: 15},
+			torrent: Torrent{Ratio: 1, SeedingDays: 1},
+			want:    true,
+		},
+		{
+			name:    "min seed days reached",
+			policy:  tracker.Policy{Trackers: []string{"tr"}, MinRatio: 1, MinSeedDays: 15},
+			torrent: Torrent{Ratio: 0.5, SeedingDays: 15},
+			want:    true,
+		},
+		{
+			name:    "minimums not reached",
+			policy:  tracker.Policy{Trackers: []string{"tr"}, MinRatio: 1, MinSeedDays: 15},
+			torrent: Torrent{Ratio: 0.5, SeedingDays: 14},
+			want:    false,
+		},
+		{
+			name:    "hnr days not satisfied",
+			policy:  tracker.Policy{Trackers: []string{"tr"}, MinRatio: 1, HnRDays: 5},
+			torrent: Torrent{Ratio: 2, SeedingDays: 4},
+			want:    false,
+		},
+		{
+			name:    "max seed days overrides hnr days",
+			policy:  tracker.Policy{Trackers: []string{"tr"}, MaxSeedDays: 3, HnRDays: 5},
+			torrent: Torrent{SeedingDays: 4},
+			want:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tracker.Init(tracker.Config{Policies: []tracker.Policy{tt.policy}}); err != nil {
+				t.Fatal(err)
+			}
+
+			tt.torrent.TrackerName = "TR"
+			if got := tt.torrent.MeetsTrackerPolicy(); got != tt.want {
+				t.Errorf("MeetsTrackerPolicy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMeetsTrackerPolicyLookups(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  tracker.Policy
+		status  int
+		body    string
+		torrent Torrent
+		want    bool
+	}{
+		{
+			name:    "not freeleech",
+			policy:  tracker.Policy{Trackers: []string{"tr"}, MinRatio: 1, IgnoreIfFreeleech: true},
+			status:  200,
+			body:    `{"data":[{"id":1,"attributes":{"freeleech":"0%"}}]}`,
+			torrent: Torrent{Ratio: 2, SeedingHours: 100},
+			want:    true,
+		},
+		{
+			name:    "freeleech",
+			policy:  tracker.Policy{Trackers: []string{"tr"}, MinRatio: 1, IgnoreIfFreeleech: true},
+			status:  200,
+			body:    `{"data":[{"id":1,"attributes":{"freeleech":"100%"}}]}`,
+			torrent: Torrent{Ratio: 2, SeedingHours: 100},
+			want:    false,
+		},
+		{
+			name:    "freeleech lookup failed",
+			policy:  tracker.Policy{Trackers: []string{"tr"}, MinRatio: 1, IgnoreIfFreeleech: true},
+			status:  401,
+			body:    `{"message":"Unauthenticated."}`,
+			torrent: Torrent{Ratio: 2, SeedingHours: 100},
+			want:    false,
+		},
+		{
+			name:    "torrent not found",
+			policy:  tracker.Policy{Trackers: []string{"tr"}, MinRatio: 1, IgnoreIfFreeleech: true},
+			status:  200,
+			body:    `{"data":[]}`,
+			torrent: Torrent{Ratio: 2, SeedingHours: 100},
+			want:    false,
+		},
+		{
+			name:    "min seed hours not reached while the api fails",
+			policy:  tracker.Policy{Trackers: []string{"tr"}, MinRatio: 1},
+			status:  401,
+			body:    `{"message":"Unauthenticated."}`,
+			torrent: Torrent{Ratio: 2, SeedingHours: 71},
+			want:    false,
+		},
+		{
+			name:    "min seed hours reached while the api fails",
+			policy:  tracker.Policy{Trackers: []string{"tr"}, MinRatio: 1},
+			status:  401,
+			body:    `{"message":"Unauthenticated."}`,
+			torrent: Torrent{Ratio: 2, SeedingHours: 72},
+			want:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			if err := tracker.Init(tracker.Config{
+				Unit3D:   map[string]tracker.Unit3DConfig{"tr": {Url: srv.URL, Key: "key", MinSeedHours: 72}},
+				Policies: []tracker.Policy{tt.policy},
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			tt.torrent.Hash = "abc"
+			tt.torrent.TrackerName = "tr"
+			if got := tt.torrent.MeetsTrackerPolicy(); got != tt.want {
+				t.Errorf("MeetsTrackerPolicy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetLookupContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":[{"id":1,"attributes":{"freeleech":"100%"}}]}`))
+	}))
+	defer srv.Close()
+
+	if err := tracker.Init(tracker.Config{
+		Unit3D: map[string]tracker.Unit3DConfig{"tr": {Url: srv.URL, Key: "key"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	torrents := map[string]Torrent{
+		"abc": {Hash: "abc", TrackerName: "tr"},
+	}
+
+	// lookups are made with the context of the torrents
+	SetLookupContext(ctx, torrents)
+	cancelled := torrents["abc"]
+	if _, err := cancelled.trackerMetadata(); !errors.Is(err, context.Canceled) {
+		t.Errorf("trackerMetadata() with a cancelled context = %v, want %v", err, context.Canceled)
+	}
+
+	SetLookupContext(context.Background(), torrents)
+	active := torrents["abc"]
+	if !active.IsFreeleech() {
+		t.Error("IsFreeleech() = false, want true")
+	}
+}
@@ -0,0 +1,244 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	TrackerErrorUnregistered = "unregistered"
+	TrackerErrorDown         = "down"
+
+	trackerErrorRegexPrefix = "regex:"
+)
+
+type TrackerErrorsConfiguration struct {
+	Categories map[string][]string
+	Trackers   []TrackerErrorsOverride
+}
+
+type TrackerErrorsOverride struct {
+	Domains    []string
+	Categories map[string][]string
+}
+
+type trackerErrorPattern struct {
+	substring string
+	regex     *regexp.Regexp
+}
+
+type trackerErrorCategory struct {
+	name     string
+	patterns []trackerErrorPattern
+}
+
+type trackerErrorOverride struct {
+	domains    []string
+	categories []trackerErrorCategory
+}
+
+/* Vars */
+
+var (
+	defaultTrackerErrors = map[string][]string{
+		TrackerErrorUnregistered: {
+			"not registered with this tracker",
+			"torrent is not authorized for use on this tracker",
+			"torrent is not found",
+			"torrent not found",
+			"torrent has been nuked",
+			"torrent does not exist",
+			"unregistered torrent",
+		},
+		TrackerErrorDown: {
+			"tracker is down",
+		},
+	}
+
+	trackerErrorCategories []trackerErrorCategory
+	trackerErrorOverrides  []trackerErrorOverride
+)
+
+/* Private */
+
+func loadTrackerErrors(cfg TrackerErrorsConfiguration) error {
+	categories, overrides, err := compileTrackerErrors(cfg)
+	if err != nil {
+		return err
+	}
+
+	trackerErrorCategories = categories
+	trackerErrorOverrides = overrides
+	return nil
+}
+
+// compileTrackerErrors compiles the patterns of the global categories (merged with the defaults) and of the tracker
+// overrides
+func compileTrackerErrors(cfg TrackerErrorsConfiguration) ([]trackerErrorCategory, []trackerErrorOverride, error) {
+	// merge configured global patterns with the defaults
+	global := make(map[string][]string)
+	for category, patterns := range defaultTrackerErrors {
+		global[category] = append(global[category], patterns...)
+	}
+	for category, patterns := range cfg.Categories {
+		category = strings.ToLower(category)
+		global[category] = append(global[category], patterns...)
+	}
+
+	categories, err := compileTrackerErrorCategories(global)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// compile tracker specific patterns
+	overrides := make([]trackerErrorOverride, 0, len(cfg.Trackers))
+	for _, o := range cfg.Trackers {
+		if len(o.Domains) == 0 {
+			return nil, nil, fmt.Errorf("tracker errors override has no domains: %+v", o)
+		}
+
+		oc := make(map[string][]string)
+		for category, patterns := range o.Categories {
+			category = strings.ToLower(category)
+			oc[category] = append(oc[category], patterns...)
+		}
+
+		compiled, err := compileTrackerErrorCategories(oc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%v: %w", o.Domains, err)
+		}
+
+		domains := make([]string, 0, len(o.Domains))
+		for _, d := range o.Domains {
+			domains = append(domains, strings.ToLower(d))
+		}
+
+		overrides = append(overrides, trackerErrorOverride{
+			domains:    domains,
+			categories: compiled,
+		})
+	}
+
+	return categories, overrides, nil
+}
+
+func compileTrackerErrorCategories(categories map[string][]string) ([]trackerErrorCategory, error) {
+	// order categories so unregistered and down always take precedence
+	names := make([]string, 0, len(categories))
+	for name := range categories {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		pi, pj := trackerErrorCategoryPriority(names[i]), trackerErrorCategoryPriority(names[j])
+		if pi != pj {
+			return pi < pj
+		}
+		return names[i] < names[j]
+	})
+
+	// compile patterns
+	compiled := make([]trackerErrorCategory, 0, len(names))
+	for _, name := range names {
+		c := trackerErrorCategory{name: name}
+
+		for _, p := range categories[name] {
+			if strings.HasPrefix(p, trackerErrorRegexPrefix) {
+				re, err := regexp.Compile("(?i)" + strings.TrimPrefix(p, trackerErrorRegexPrefix))
+				if err != nil {
+					return nil, fmt.Errorf("compile %s pattern: %q: %w", name, p, err)
+				}
+
+				c.patterns = append(c.patterns, trackerErrorPattern{regex: re})
+				continue
+			}
+
+			c.patterns = append(c.patterns, trackerErrorPattern{substring: strings.ToLower(p)})
+		}
+
+		compiled = append(compiled, c)
+	}
+
+	return compiled, nil
+}
+
+func trackerErrorCategoryPriority(name string) int {
+	switch name {
+	case TrackerErrorUnregistered:
+		return 0
+	case TrackerErrorDown:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func (c *trackerErrorCategory) match(status string) bool {
+	for _, p := range c.patterns {
+		if p.regex != nil {
+			if p.regex.MatchString(status) {
+				return true
+			}
+			continue
+		}
+
+		if strings.Contains(status, p.substring) {
+			return true
+		}
+	}
+
+	return false
+}
+
+/* Public */
+
+// GetTrackerErrorCategory returns the category matching the tracker status, checking tracker specific
+// patterns before the global ones. An empty string is returned when no category matched.
+func GetTrackerErrorCategory(trackerName string, trackerHost string, trackerStatus string) string {
+	if trackerStatus == "" {
+		return ""
+	}
+
+	if trackerErrorCategories == nil {
+		// config was not loaded, fallback to the defaults
+		if err := loadTrackerErrors(TrackerErrorsConfiguration{}); err != nil {
+			return ""
+		}
+	}
+
+	status := strings.ToLower(trackerStatus)
+	name := strings.ToLower(trackerName)
+	host := strings.ToLower(trackerHost)
+
+	// check tracker specific patterns
+	for _, o := range trackerErrorOverrides {
+		matched := false
+		for _, d := range o.domains {
+			if strings.Contains(name, d) || strings.Contains(host, d) {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			continue
+		}
+
+		for _, c := range o.categories {
+			if c.match(status) {
+				return c.name
+			}
+		}
+	}
+
+	// check global patterns
+	for _, c := range trackerErrorCategories {
+		if c.match(status) {
+			return c.name
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestGetTrackerErrorCategory(t *testing.T) {
+	cfg := TrackerErrorsConfiguration{
+		Categories: map[string][]string{
+			"Unregistered":   {"regex:^torrent (was )?deleted"},
+			"rate_limited":   {"too many requests"},
+			TrackerErrorDown: {"regex:5\\d\\d"},
+		},
+		Trackers: []TrackerErrorsOverride{
+			{
+				Domains:    []string{"example.org"},
+				Categories: map[string][]string{"unregistered": {"gone"}},
+			},
+		},
+	}
+	if err := loadTrackerErrors(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		tracker string
+		host    string
+		status  string
+		want    string
+	}{
+		{name: "empty status", status: "", want: ""},
+		{name: "working", status: "Working", want: ""},
+		{name: "default pattern", status: "Unregistered torrent", want: TrackerErrorUnregistered},
+		{name: "default pattern case", status: "TORRENT NOT FOUND", want: TrackerErrorUnregistered},
+		{name: "default down", status: "Tracker is down", want: TrackerErrorDown},
+		{name: "regex", status: "Torrent was deleted by staff", want: TrackerErrorUnregistered},
+		{name: "regex anchored", status: "the torrent was deleted", want: ""},
+		{name: "custom category", status: "Too Many Requests", want: "rate_limited"},
+		{name: "regex down", status: "HTTP 503", want: TrackerErrorDown},
+		{name: "unregistered takes precedence", status: "unregistered torrent: 503", want: TrackerErrorUnregistered},
+		{name: "override by host", host: "tracker.example.org", status: "Gone", want: TrackerErrorUnregistered},
+		{name: "override by name", tracker: "example.org", status: "gone", want: TrackerErrorUnregistered},
+		{name: "override for other trackers", host: "tracker.other.org", status: "gone", want: ""},
+		{name: "override falls back to global", host: "tracker.example.org", status: "Tracker is down",
+			want: TrackerErrorDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetTrackerErrorCategory(tt.tracker, tt.host, tt.status); got != tt.want {
+				t.Errorf("GetTrackerErrorCategory(%q, %q, %q) = %q, want %q", tt.tracker, tt.host, tt.status,
+					got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadTrackerErrorsInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  TrackerErrorsConfiguration
+	}{
+		{name: "invalid regex", cfg: TrackerErrorsConfiguration{
+			Categories: map[string][]string{"unregistered": {"regex:("}},
+		}},
+		{name: "override without domains", cfg: TrackerErrorsConfiguration{
+			Trackers: []TrackerErrorsOverride{{Categories: map[string][]string{"unregistered": {"gone"}}}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := loadTrackerErrors(tt.cfg); err == nil {
+				t.Error("loadTrackerErrors() error = nil, want error")
+			}
+		})
+	}
+}
@@ -2,8 +2,12 @@ package config
 
 import (
 	"fmt"
+	"net/url"
+	"os"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -18,6 +22,18 @@ type Validator interface {
 	Validate(reflect.Value) (bool, error)
 }
 
+// ValidationErrors aggregates the errors of all invalid settings
+type ValidationErrors []error
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
 /* Validators */
 
 // - Default
@@ -35,53 +51,304 @@ type RequiredValidator struct {
 }
 
 func (v RequiredValidator) Validate(val reflect.Value) (bool, error) {
-	if val.IsNil() {
-		return false, errors.New("required setting not set")
+	switch val.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if val.IsNil() {
+			return false, errors.New("required setting not set")
+		}
+	case reflect.String, reflect.Slice, reflect.Map:
+		if val.Len() == 0 {
+			return false, errors.New("required setting not set")
+		}
+	default:
+		if val.IsZero() {
+			return false, errors.New("required setting not set")
+		}
 	}
 	return true, nil
 }
 
-/* Private */
+// - Url
+
+type UrlValidator struct {
+}
+
+func (v UrlValidator) Validate(val reflect.Value) (bool, error) {
+	// validate each url of a list
+	if val.Kind() == reflect.Slice {
+		for i := 0; i < val.Len(); i++ {
+			if valid, err := v.Validate(val.Index(i)); !valid {
+				return valid, err
+			}
+		}
+		return true, nil
+	}
 
-func getValidatorFromTag(tag string) Validator {
-	args := strings.Split(tag, ",")
+	s, ok := stringValue(val)
+	if !ok || s == "" {
+		return true, nil
+	}
 
-	switch args[0] {
-	case "required":
-		return RequiredValidator{}
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return false, fmt.Errorf("must be a http(s) url, got %q", s)
 	}
+	return true, nil
+}
 
-	return DefaultValidator{}
+// - OneOf
+
+type OneOfValidator struct {
+	Values []string
 }
 
-/* Public */
+func (v OneOfValidator) Validate(val reflect.Value) (bool, error) {
+	s, ok := stringValue(val)
+	if !ok || s == "" {
+		return true, nil
+	}
 
-func ValidateStruct(s interface{}) []error {
-	var errs []error
+	for _, value := range v.Values {
+		if strings.EqualFold(s, value) {
+			return true, nil
+		}
+	}
+	return false, fmt.Errorf("must be one of %s, got %q", strings.Join(v.Values, ", "), s)
+}
+
+// - Min
+
+type MinValidator struct {
+	Min float64
+}
+
+func (v MinValidator) Validate(val reflect.Value) (bool, error) {
+	if n, isLen, ok := numericValue(val); ok && n < v.Min {
+		if isLen {
+			return false, fmt.Errorf("must have at least %v entries", v.Min)
+		}
+		return false, fmt.Errorf("must be at least %v, got %v", v.Min, n)
+	}
+	return true, nil
+}
+
+// - Max
+
+type MaxValidator struct {
+	Max float64
+}
+
+func (v MaxValidator) Validate(val reflect.Value) (bool, error) {
+	if n, isLen, ok := numericValue(val); ok && n > v.Max {
+		if isLen {
+			return false, fmt.Errorf("must have at most %v entries", v.Max)
+		}
+		return false, fmt.Errorf("must be at most %v, got %v", v.Max, n)
+	}
+	return true, nil
+}
+
+// - PathExists
+
+type PathExistsValidator struct {
+}
+
+func (v PathExistsValidator) Validate(val reflect.Value) (bool, error) {
+	s, ok := stringValue(val)
+	if !ok || s == "" {
+		return true, nil
+	}
+
+	if _, err := os.Stat(s); err != nil {
+		return false, fmt.Errorf("path does not exist: %q", s)
+	}
+	return true, nil
+}
+
+// - Duration
+
+type DurationValidator struct {
+}
+
+func (v DurationValidator) Validate(val reflect.Value) (bool, error) {
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return true, nil
+		}
+		val = val.Elem()
+	}
+
+	switch {
+	case val.Type() == reflect.TypeOf(time.Duration(0)):
+		if d := time.Duration(val.Int()); d < 0 {
+			return false, fmt.Errorf("must not be a negative duration, got %s", d)
+		}
+	case val.Kind() == reflect.String:
+		if s := val.String(); s != "" {
+			if _, err := time.ParseDuration(s); err != nil {
+				return false, fmt.Errorf("must be a duration (e.g. 90s, 1h30m), got %q", s)
+			}
+		}
+	}
+	return true, nil
+}
+
+/* Private */
+
+func getValidatorsFromTag(tag string) []Validator {
+	validators := make([]Validator, 0)
 
-	// ValueOf returns a Value representing the run-time data
-	v := reflect.ValueOf(s)
+	for _, rule := range strings.Split(tag, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
 
-	for i := 0; i < v.NumField(); i++ {
-		// Get the field tag value
-		tag := v.Type().Field(i).Tag.Get(tagName)
+		switch name {
+		case "required":
+			validators = append(validators, RequiredValidator{})
+		case "url":
+			validators = append(validators, UrlValidator{})
+		case "oneof":
+			validators = append(validators, OneOfValidator{Values: strings.Fields(arg)})
+		case "min":
+			if n, err := strconv.ParseFloat(arg, 64); err == nil {
+				validators = append(validators, MinValidator{Min: n})
+			}
+		case "max":
+			if n, err := strconv.ParseFloat(arg, 64); err == nil {
+				validators = append(validators, MaxValidator{Max: n})
+			}
+		case "path_exists":
+			validators = append(validators, PathExistsValidator{})
+		case "duration":
+			validators = append(validators, DurationValidator{})
+		default:
+			validators = append(validators, DefaultValidator{})
+		}
+	}
+
+	return validators
+}
 
-		// Skip if tag is not defined or ignored
-		if tag == "" || tag == "-" {
-			continue
+func validateValue(path string, v reflect.Value) []error {
+	var errs []error
+
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
 		}
+		v = v.Elem()
+	}
 
-		// Get a validator that corresponds to a tag
-		validator := getValidatorFromTag(tag)
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
 
-		// Perform validation
-		valid, err := validator.Validate(v.Field(i))
+			// Get the field tag value
+			tag := field.Tag.Get(tagName)
 
-		// Append error to results
-		if !valid && err != nil {
-			errs = append(errs, fmt.Errorf("%q: %s", v.Type().Field(i).Name, err.Error()))
+			// Skip if tag is ignored
+			if tag == "-" {
+				continue
+			}
+
+			// Validate squashed structs as part of this struct
+			name, squash := settingName(field)
+			fieldPath := path
+			if !squash {
+				fieldPath = joinSettingPath(path, name)
+			}
+
+			// Perform validation
+			if tag != "" {
+				for _, validator := range getValidatorsFromTag(tag) {
+					valid, err := validator.Validate(v.Field(i))
+
+					// Append error to results
+					if !valid && err != nil {
+						errs = append(errs, fmt.Errorf("%s: %s", fieldPath, err.Error()))
+					}
+				}
+			}
+
+			errs = append(errs, validateValue(fieldPath, v.Field(i))...)
+		}
+	case reflect.Map:
+		for _, k := range v.MapKeys() {
+			errs = append(errs, validateValue(joinSettingPath(path, fmt.Sprint(k.Interface())), v.MapIndex(k))...)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			errs = append(errs, validateValue(fmt.Sprintf("%s[%d]", path, i), v.Index(i))...)
 		}
 	}
 
 	return errs
 }
+
+// settingName returns the configuration key of the field and whether it is squashed into its parent
+func settingName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("koanf")
+	name, opts, _ := strings.Cut(tag, ",")
+	if opts == "squash" {
+		return "", true
+	}
+
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name, false
+}
+
+func joinSettingPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + Delimiter + name
+}
+
+func stringValue(val reflect.Value) (string, bool) {
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return "", false
+		}
+		val = val.Elem()
+	}
+
+	if val.Kind() != reflect.String {
+		return "", false
+	}
+	return val.String(), true
+}
+
+// numericValue returns the number, or length, of the value to compare against bounds
+func numericValue(val reflect.Value) (float64, bool, bool) {
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return 0, false, false
+		}
+		val = val.Elem()
+	}
+
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(val.Int()), false, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(val.Uint()), false, true
+	case reflect.Float32, reflect.Float64:
+		return val.Float(), false, true
+	case reflect.String, reflect.Slice, reflect.Map:
+		return float64(val.Len()), true, true
+	}
+
+	return 0, false, false
+}
+
+/* Public */
+
+// ValidateStruct validates the settings of the struct, and the structs nested within it, against their tags
+func ValidateStruct(s interface{}) []error {
+	return validateValue("", reflect.ValueOf(s))
+}
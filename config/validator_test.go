@@ -0,0 +1,156 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValidateStruct(t *testing.T) {
+	valid := ClientConfiguration{Type: "qbittorrent", Filter: []string{"default"}}
+
+	tests := []struct {
+		name   string
+		config interface{}
+		want   []string
+	}{
+		{
+			name: "valid qbittorrent",
+			config: &QBittorrentConfiguration{
+				ClientConfiguration: valid,
+				Url:                 "http://localhost:8080",
+			},
+		},
+		{
+			name: "missing required settings",
+			config: &QBittorrentConfiguration{
+				ClientConfiguration: ClientConfiguration{Type: "QBittorrent"},
+			},
+			want: []string{
+				"filter: required setting not set",
+				"url: required setting not set",
+			},
+		},
+		{
+			name: "invalid values",
+			config: &QBittorrentConfiguration{
+				ClientConfiguration: ClientConfiguration{
+					Type:           "rtorrent",
+					Filter:         []string{"default"},
+					ConnectTimeout: -time.Second,
+				},
+				Url: "localhost:8080",
+			},
+			want: []string{
+				`type: must be one of deluge, qbittorrent, got "rtorrent"`,
+				"connect_timeout: must not be a negative duration, got -1s",
+				`url: must be a http(s) url, got "localhost:8080"`,
+			},
+		},
+		{
+			name: "missing path",
+			config: &struct {
+				Path string `validate:"path_exists"`
+			}{Path: "/does/not/exist"},
+			want: []string{`path: path does not exist: "/does/not/exist"`},
+		},
+		{
+			name: "out of range port",
+			config: &DelugeConfiguration{
+				ClientConfiguration: ClientConfiguration{Type: "deluge", Filter: []string{"default"}},
+				Host:                "localhost",
+				Port:                70000,
+				Login:               "user",
+				Password:            "pass",
+			},
+			want: []string{"port: must be at most 65535, got 70000"},
+		},
+		{
+			name: "nested settings",
+			config: map[string]interface{}{
+				"clients": map[string]DelugeConfiguration{
+					"deluge": {
+						ClientConfiguration: ClientConfiguration{Type: "deluge", Filter: []string{"default"}},
+						Host:                "localhost",
+						Login:               "user",
+						Password:            "pass",
+					},
+				},
+			},
+			want: []string{
+				"clients.deluge.port: required setting not set",
+				"clients.deluge.port: must be at least 1, got 0",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, err := range ValidateStruct(tt.config) {
+				got = append(got, err.Error())
+			}
+
+			want := tt.want
+			if want == nil {
+				want = []string{}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ValidateStruct() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestValidators(t *testing.T) {
+	tests := []struct {
+		name      string
+		validator Validator
+		value     interface{}
+		want      bool
+	}{
+		{"required empty slice", RequiredValidator{}, []string{}, false},
+		{"required zero duration", RequiredValidator{}, time.Duration(0), false},
+		{"required nil pointer", RequiredValidator{}, (*string)(nil), false},
+		{"required set", RequiredValidator{}, "value", true},
+		{"oneof ignores case", OneOfValidator{Values: []string{"a", "b"}}, "B", true},
+		{"oneof allows empty", OneOfValidator{Values: []string{"a", "b"}}, "", true},
+		{"oneof invalid", OneOfValidator{Values: []string{"a", "b"}}, "c", false},
+		{"min entries", MinValidator{Min: 2}, []string{"a"}, false},
+		{"min number", MinValidator{Min: 1}, 1, true},
+		{"max float", MaxValidator{Max: 100}, 100.5, false},
+		{"max ignores bool", MaxValidator{Max: 0}, true, true},
+		{"url list", UrlValidator{}, []string{"https://a.example.com", "ftp://b.example.com"}, false},
+		{"url empty", UrlValidator{}, "", true},
+		{"duration string", DurationValidator{}, "1h30m", true},
+		{"duration invalid string", DurationValidator{}, "1 hour", false},
+		{"path exists", PathExistsValidator{}, ".", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.validator.Validate(reflect.ValueOf(tt.value))
+			if got != tt.want {
+				t.Errorf("Validate() = %v (%v), want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckDownloadPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{path: ""},
+		{path: t.TempDir()},
+		{path: "/does/not/exist", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		err := ClientConfiguration{DownloadPath: tt.path}.CheckDownloadPath()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CheckDownloadPath() with %q = %v, want error: %v", tt.path, err, tt.wantErr)
+		}
+	}
+}
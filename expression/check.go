@@ -10,23 +10,33 @@ import (
 )
 
 func CheckTorrentSingleMatch(t *config.Torrent, exp []*vm.Program) (bool, error) {
-	for _, expression := range exp {
+	match, err := CheckTorrentFirstMatch(t, exp)
+	if err != nil {
+		return false, err
+	}
+
+	return match >= 0, nil
+}
+
+// CheckTorrentFirstMatch returns the index of the first matching expression, or -1 if none matched
+func CheckTorrentFirstMatch(t *config.Torrent, exp []*vm.Program) (int, error) {
+	for i, expression := range exp {
 		result, err := expr.Run(expression, t)
 		if err != nil {
-			return false, fmt.Errorf("check expression: %w", err)
+			return -1, fmt.Errorf("check expression: %w", err)
 		}
 
 		expResult, ok := result.(bool)
 		if !ok {
-			return false, fmt.Errorf("type assert expression result: %w", err)
+			return -1, fmt.Errorf("type assert expression result: %w", err)
 		}
 
 		if expResult {
-			return true, nil
+			return i, nil
 		}
 	}
 
-	return false, nil
+	return -1, nil
 }
 
 func CheckTorrentAllMatch(t *config.Torrent, exp []*vm.Program) (bool, error) {
@@ -2,6 +2,7 @@ package expression
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/antonmedv/expr"
 
@@ -12,38 +13,57 @@ func Compile(filter *config.FilterConfiguration) (*Expressions, error) {
 	exprEnv := &config.Torrent{}
 	exp := new(Expressions)
 
+	// compile macros
+	m, err := newMacros(filter.Macros)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range m.names() {
+		if _, err := m.compile(filter.Macros[name], expr.Env(exprEnv)); err != nil {
+			return nil, fmt.Errorf("compile macro %q: %q: %w", name, filter.Macros[name], err)
+		}
+
+		exp.collectNames(filter.Macros[name])
+	}
+
 	// compile ignores
 	for _, ignoreExpr := range filter.Ignore {
-		program, err := expr.Compile(ignoreExpr, expr.Env(exprEnv), expr.AsBool())
+		program, err := m.compile(ignoreExpr, expr.Env(exprEnv), expr.AsBool())
 		if err != nil {
 			return nil, fmt.Errorf("compile ignore expression: %q: %w", ignoreExpr, err)
 		}
 
 		exp.Ignores = append(exp.Ignores, program)
+		exp.ignoreSources = append(exp.ignoreSources, ignoreExpr)
+		exp.collectNames(ignoreExpr)
 	}
 
 	// compile removes
 	for _, removeExpr := range filter.Remove {
-		program, err := expr.Compile(removeExpr, expr.Env(exprEnv), expr.AsBool())
+		program, err := m.compile(removeExpr, expr.Env(exprEnv), expr.AsBool())
 		if err != nil {
 			return nil, fmt.Errorf("compile remove expression: %q: %w", removeExpr, err)
 		}
 
 		exp.Removes = append(exp.Removes, program)
+		exp.removeSources = append(exp.removeSources, removeExpr)
+		exp.collectNames(removeExpr)
 	}
 
 	// compile labels
 	for _, labelExpr := range filter.Label {
-		le := &LabelExpression{Name: labelExpr.Name}
+		le := &LabelExpression{Name: labelExpr.Name, rule: strings.Join(labelExpr.Update, " && ")}
 
 		// compile updates
 		for _, updateExpr := range labelExpr.Update {
-			program, err := expr.Compile(updateExpr, expr.Env(exprEnv), expr.AsBool())
+			program, err := m.compile(updateExpr, expr.Env(exprEnv), expr.AsBool())
 			if err != nil {
 				return nil, fmt.Errorf("compile label update expression: %v: %q: %w", labelExpr.Name, updateExpr, err)
 			}
 
 			le.Updates = append(le.Updates, program)
+			exp.collectNames(updateExpr)
 		}
 
 		exp.Labels = append(exp.Labels, le)
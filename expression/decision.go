@@ -0,0 +1,82 @@
+package expression
+
+import (
+	"fmt"
+
+	"github.com/l3uddz/tqm/config"
+)
+
+const (
+	ActionIgnore = "ignore"
+	ActionRemove = "remove"
+	ActionLabel  = "label"
+	ActionNone   = "none"
+)
+
+// Decision is the action of the filter for a torrent, with the expression that matched
+type Decision struct {
+	Action string
+	Label  string
+	Rule   string
+}
+
+/* Public */
+
+// CheckIgnore returns whether the torrent is ignored, by the first matching ignore expression
+func (e *Expressions) CheckIgnore(t *config.Torrent) (*Decision, error) {
+	match, err := CheckTorrentFirstMatch(t, e.Ignores)
+	if err != nil {
+		return nil, fmt.Errorf("check ignore expression: %w", err)
+	} else if match < 0 {
+		return &Decision{Action: ActionNone}, nil
+	}
+
+	return &Decision{Action: ActionIgnore, Rule: e.ignoreSources[match]}, nil
+}
+
+// CheckRemove returns whether the torrent is removed, by the first matching remove expression
+func (e *Expressions) CheckRemove(t *config.Torrent) (*Decision, error) {
+	match, err := CheckTorrentFirstMatch(t, e.Removes)
+	if err != nil {
+		return nil, fmt.Errorf("check remove expression: %w", err)
+	} else if match < 0 {
+		return &Decision{Action: ActionNone}, nil
+	}
+
+	return &Decision{Action: ActionRemove, Rule: e.removeSources[match]}, nil
+}
+
+// CheckRemoval returns whether the torrent is ignored or removed by the clean command, ignore expressions take
+// precedence over remove expressions
+func (e *Expressions) CheckRemoval(t *config.Torrent) (*Decision, error) {
+	d, err := e.CheckIgnore(t)
+	if err != nil || d.Action != ActionNone {
+		return d, err
+	}
+
+	return e.CheckRemove(t)
+}
+
+// CheckLabel returns the first label whose update expressions all match the torrent, torrents are relabeled
+// regardless of the ignore and remove expressions
+func (e *Expressions) CheckLabel(t *config.Torrent) (*Decision, error) {
+	for _, label := range e.Labels {
+		match, err := CheckTorrentAllMatch(t, label.Updates)
+		if err != nil {
+			return nil, fmt.Errorf("check update expression: %w", err)
+		} else if match {
+			return &Decision{Action: ActionLabel, Label: label.Name, Rule: label.rule}, nil
+		}
+	}
+
+	return &Decision{Action: ActionNone}, nil
+}
+
+// String returns the decision as written by filter tests, e.g. remove or label:NAME
+func (d *Decision) String() string {
+	if d.Action == ActionLabel {
+		return ActionLabel + ":" + d.Label
+	}
+
+	return d.Action
+}
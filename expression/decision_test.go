@@ -0,0 +1,88 @@
+package expression
+
+import (
+	"testing"
+
+	"github.com/l3uddz/tqm/config"
+)
+
+func TestDecisions(t *testing.T) {
+	exp, err := Compile(&config.FilterConfiguration{
+		Ignore: []string{`Label == "keep"`},
+		Remove: []string{"Ratio > 2.0", "SeedingDays > 30"},
+		Label: []config.FilterLabelConfiguration{
+			{Name: "slow", Update: []string{"Seeds < 3", `Label != "slow"`}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		torrent config.Torrent
+		check   func(*config.Torrent) (*Decision, error)
+		want    string
+		rule    string
+	}{
+		{
+			name:    "ignore",
+			torrent: config.Torrent{Label: "keep"},
+			check:   exp.CheckIgnore,
+			want:    "ignore",
+			rule:    `Label == "keep"`,
+		},
+		{
+			name:    "not ignored",
+			torrent: config.Torrent{Ratio: 3},
+			check:   exp.CheckIgnore,
+			want:    "none",
+		},
+		{
+			name:    "remove regardless of ignore",
+			torrent: config.Torrent{Label: "keep", SeedingDays: 40},
+			check:   exp.CheckRemove,
+			want:    "remove",
+			rule:    "SeedingDays > 30",
+		},
+		{
+			name:    "removal ignored",
+			torrent: config.Torrent{Label: "keep", Ratio: 3},
+			check:   exp.CheckRemoval,
+			want:    "ignore",
+			rule:    `Label == "keep"`,
+		},
+		{
+			name:    "removal by the first matching remove",
+			torrent: config.Torrent{Ratio: 3, SeedingDays: 40},
+			check:   exp.CheckRemoval,
+			want:    "remove",
+			rule:    "Ratio > 2.0",
+		},
+		{
+			name:    "label",
+			torrent: config.Torrent{Label: "keep", Seeds: 1},
+			check:   exp.CheckLabel,
+			want:    "label:slow",
+			rule:    `Seeds < 3 && Label != "slow"`,
+		},
+		{
+			name:    "label requires all updates",
+			torrent: config.Torrent{Label: "slow", Seeds: 1},
+			check:   exp.CheckLabel,
+			want:    "none",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.check(&tt.torrent)
+			if err != nil {
+				t.Fatalf("check error = %v", err)
+			}
+			if got.String() != tt.want || got.Rule != tt.rule {
+				t.Errorf("check = %s (%s), want %s (%s)", got, got.Rule, tt.want, tt.rule)
+			}
+		})
+	}
+}
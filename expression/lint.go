@@ -0,0 +1,237 @@
+package expression
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/ast"
+	"github.com/antonmedv/expr/parser"
+
+	"github.com/l3uddz/tqm/config"
+)
+
+type envReferenceDetector struct {
+	found bool
+}
+
+type trackerReferenceCollector struct {
+	trackers map[string]bool
+}
+
+/* Public */
+
+// Lint returns warnings about expressions of the filter which can never, or will always, match
+func Lint(filter *config.FilterConfiguration) ([]string, error) {
+	m, err := newMacros(filter.Macros)
+	if err != nil {
+		return nil, err
+	}
+
+	warnings := make([]string, 0)
+	warnings = append(warnings, lintSection(m, "ignore", filter.Ignore)...)
+	warnings = append(warnings, lintSection(m, "remove", filter.Remove)...)
+
+	// always true ignores prevent any removal
+	for _, e := range filter.Ignore {
+		if v, ok := constantValue(m, e); ok && v && len(filter.Remove) > 0 {
+			warnings = append(warnings, fmt.Sprintf("ignore expression %q ignores every torrent, "+
+				"remove expressions are unreachable", e))
+			break
+		}
+	}
+
+	// labels
+	for i, label := range filter.Label {
+		if len(label.Update) == 0 {
+			warnings = append(warnings, fmt.Sprintf("label %q has no update expressions and matches every torrent",
+				label.Name))
+		}
+
+		alwaysTrue := true
+		for _, e := range label.Update {
+			v, ok := constantValue(m, e)
+			switch {
+			case ok && !v:
+				warnings = append(warnings, fmt.Sprintf("label %q update expression %q never matches",
+					label.Name, e))
+				alwaysTrue = false
+			case !ok:
+				alwaysTrue = false
+			}
+		}
+
+		if alwaysTrue && i < len(filter.Label)-1 {
+			warnings = append(warnings, fmt.Sprintf("label %q matches every torrent, later labels are unreachable",
+				label.Name))
+		}
+
+		// earlier labels whose updates are a subset of this labels updates shadow it
+		for _, earlier := range filter.Label[:i] {
+			if isExpressionSubset(earlier.Update, label.Update) {
+				warnings = append(warnings, fmt.Sprintf("label %q is shadowed by earlier label %q",
+					label.Name, earlier.Name))
+				break
+			}
+		}
+	}
+
+	return warnings, nil
+}
+
+// TrackerReferences returns the tracker names and hosts compared against by the filter
+func TrackerReferences(filter *config.FilterConfiguration) []string {
+	c := &trackerReferenceCollector{trackers: make(map[string]bool)}
+
+	sources := make([]string, 0)
+	sources = append(sources, filter.Ignore...)
+	sources = append(sources, filter.Remove...)
+	for _, label := range filter.Label {
+		sources = append(sources, label.Update...)
+	}
+	for _, macro := range filter.Macros {
+		sources = append(sources, macro)
+	}
+
+	for _, source := range sources {
+		tree, err := parser.Parse(source)
+		if err != nil {
+			continue
+		}
+
+		ast.Walk(&tree.Node, c)
+	}
+
+	trackers := make([]string, 0, len(c.trackers))
+	for t := range c.trackers {
+		trackers = append(trackers, t)
+	}
+
+	return trackers
+}
+
+/* Private */
+
+func lintSection(m *macros, section string, expressions []string) []string {
+	warnings := make([]string, 0)
+	seen := make(map[string]bool)
+	unreachable := false
+
+	for _, e := range expressions {
+		normalized := normalizeExpression(e)
+		switch {
+		case unreachable:
+			warnings = append(warnings, fmt.Sprintf("%s expression %q is unreachable", section, e))
+			continue
+		case seen[normalized]:
+			warnings = append(warnings, fmt.Sprintf("%s expression %q is duplicated", section, e))
+			continue
+		}
+		seen[normalized] = true
+
+		if v, ok := constantValue(m, e); ok {
+			if v {
+				warnings = append(warnings, fmt.Sprintf("%s expression %q always matches", section, e))
+				unreachable = true
+			} else {
+				warnings = append(warnings, fmt.Sprintf("%s expression %q never matches", section, e))
+			}
+		}
+	}
+
+	return warnings
+}
+
+// constantValue evaluates expressions which do not reference the torrent
+func constantValue(m *macros, expression string) (bool, bool) {
+	tree, err := parser.Parse(expression)
+	if err != nil {
+		return false, false
+	}
+
+	p := m.patcher()
+	ast.Walk(&tree.Node, p)
+	if p.err != nil {
+		return false, false
+	}
+
+	d := new(envReferenceDetector)
+	ast.Walk(&tree.Node, d)
+	if d.found {
+		return false, false
+	}
+
+	program, err := m.compile(expression, expr.Env(&config.Torrent{}), expr.AsBool())
+	if err != nil {
+		return false, false
+	}
+
+	result, err := expr.Run(program, &config.Torrent{})
+	if err != nil {
+		return false, false
+	}
+
+	v, ok := result.(bool)
+	return v, ok
+}
+
+func normalizeExpression(expression string) string {
+	return strings.Join(strings.Fields(expression), " ")
+}
+
+func isExpressionSubset(subset []string, set []string) bool {
+	if len(subset) == 0 {
+		return true
+	}
+
+	normalized := make(map[string]bool)
+	for _, e := range set {
+		normalized[normalizeExpression(e)] = true
+	}
+
+	for _, e := range subset {
+		if !normalized[normalizeExpression(e)] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (d *envReferenceDetector) Enter(_ *ast.Node) {}
+
+func (d *envReferenceDetector) Exit(node *ast.Node) {
+	switch (*node).(type) {
+	case *ast.IdentifierNode, *ast.FunctionNode, *ast.MethodNode:
+		d.found = true
+	}
+}
+
+func (c *trackerReferenceCollector) Enter(_ *ast.Node) {}
+
+func (c *trackerReferenceCollector) Exit(node *ast.Node) {
+	n, ok := (*node).(*ast.BinaryNode)
+	if !ok {
+		return
+	}
+
+	// find the tracker identifier and the value compared against
+	var value ast.Node
+	for _, pair := range [][2]ast.Node{{n.Left, n.Right}, {n.Right, n.Left}} {
+		if id, ok := pair[0].(*ast.IdentifierNode); ok && (id.Value == "TrackerName" || id.Value == "TrackerHost") {
+			value = pair[1]
+			break
+		}
+	}
+
+	switch v := value.(type) {
+	case *ast.StringNode:
+		c.trackers[v.Value] = true
+	case *ast.ArrayNode:
+		for _, item := range v.Nodes {
+			if s, ok := item.(*ast.StringNode); ok {
+				c.trackers[s.Value] = true
+			}
+		}
+	}
+}
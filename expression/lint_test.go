@@ -0,0 +1,126 @@
+package expression
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/l3uddz/tqm/config"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter config.FilterConfiguration
+		want   []string
+	}{
+		{
+			name: "no warnings",
+			filter: config.FilterConfiguration{
+				Ignore: []string{"Label == \"keep\""},
+				Remove: []string{"Ratio > 2.0", "IsUnregistered()"},
+				Label: []config.FilterLabelConfiguration{
+					{Name: "a", Update: []string{"Seeds < 3"}},
+					{Name: "b", Update: []string{"Seeds > 10"}},
+				},
+			},
+			want: []string{},
+		},
+		{
+			name: "duplicated expression",
+			filter: config.FilterConfiguration{
+				Remove: []string{"Ratio > 2.0", "Ratio  >  2.0"},
+			},
+			want: []string{`remove expression "Ratio  >  2.0" is duplicated`},
+		},
+		{
+			name: "constant expressions",
+			filter: config.FilterConfiguration{
+				Remove: []string{"1 > 2", "true", "Ratio > 2.0"},
+			},
+			want: []string{
+				`remove expression "1 > 2" never matches`,
+				`remove expression "true" always matches`,
+				`remove expression "Ratio > 2.0" is unreachable`,
+			},
+		},
+		{
+			name: "constant macro",
+			filter: config.FilterConfiguration{
+				Macros: map[string]string{"Never": "false"},
+				Remove: []string{"Never"},
+			},
+			want: []string{`remove expression "Never" never matches`},
+		},
+		{
+			name: "ignore matching every torrent",
+			filter: config.FilterConfiguration{
+				Ignore: []string{"1 == 1"},
+				Remove: []string{"Ratio > 2.0"},
+			},
+			want: []string{
+				`ignore expression "1 == 1" always matches`,
+				`ignore expression "1 == 1" ignores every torrent, remove expressions are unreachable`,
+			},
+		},
+		{
+			name: "label matching every torrent",
+			filter: config.FilterConfiguration{
+				Label: []config.FilterLabelConfiguration{
+					{Name: "all"},
+					{Name: "later", Update: []string{"Seeds < 3"}},
+				},
+			},
+			want: []string{
+				`label "all" has no update expressions and matches every torrent`,
+				`label "all" matches every torrent, later labels are unreachable`,
+				`label "later" is shadowed by earlier label "all"`,
+			},
+		},
+		{
+			name: "shadowed label",
+			filter: config.FilterConfiguration{
+				Label: []config.FilterLabelConfiguration{
+					{Name: "broad", Update: []string{"Seeds < 3"}},
+					{Name: "narrow", Update: []string{"Seeds  < 3", "Ratio > 1.0"}},
+					{Name: "never", Update: []string{"false"}},
+				},
+			},
+			want: []string{
+				`label "narrow" is shadowed by earlier label "broad"`,
+				`label "never" update expression "false" never matches`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Lint(&tt.filter)
+			if err != nil {
+				t.Fatalf("Lint() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lint() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrackerReferences(t *testing.T) {
+	filter := &config.FilterConfiguration{
+		Macros: map[string]string{"Private": "TrackerName in [\"bhd\", \"ptp\"]"},
+		Ignore: []string{"\"tracker.example.com\" == TrackerHost"},
+		Remove: []string{"TrackerName == \"hdb\" && Ratio > 1.0", "Label == \"tv\"", "invalid ("},
+		Label: []config.FilterLabelConfiguration{
+			{Name: "a", Update: []string{"TrackerHost != \"other.example.com\""}},
+		},
+	}
+
+	got := TrackerReferences(filter)
+	sort.Strings(got)
+
+	want := []string{"bhd", "hdb", "other.example.com", "ptp", "tracker.example.com"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TrackerReferences() = %v, want %v", got, want)
+	}
+}
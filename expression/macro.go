@@ -0,0 +1,125 @@
+package expression
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/ast"
+	"github.com/antonmedv/expr/parser"
+	"github.com/antonmedv/expr/vm"
+
+	"github.com/l3uddz/tqm/config"
+)
+
+type macros struct {
+	sources   map[string]string
+	expanding []string
+}
+
+type macroPatcher struct {
+	macros *macros
+	err    error
+}
+
+/* Private */
+
+func newMacros(sources map[string]string) (*macros, error) {
+	m := &macros{sources: sources}
+
+	// macros cannot shadow torrent fields or functions
+	env := reflect.TypeOf(&config.Torrent{})
+	for _, name := range m.names() {
+		if _, ok := env.Elem().FieldByName(name); ok {
+			return nil, fmt.Errorf("compile macro %q: name is already used by a torrent field", name)
+		}
+		if _, ok := env.MethodByName(name); ok {
+			return nil, fmt.Errorf("compile macro %q: name is already used by a torrent function", name)
+		}
+	}
+
+	return m, nil
+}
+
+func (m *macros) names() []string {
+	names := make([]string, 0, len(m.sources))
+	for name := range m.sources {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func (m *macros) expand(name string) (ast.Node, error) {
+	// detect macros referencing themselves
+	for i, n := range m.expanding {
+		if n == name {
+			cycle := append(append([]string{}, m.expanding[i:]...), name)
+			return nil, fmt.Errorf("macro %q: reference cycle: %s", name, strings.Join(cycle, " -> "))
+		}
+	}
+
+	tree, err := parser.Parse(m.sources[name])
+	if err != nil {
+		return nil, fmt.Errorf("macro %q: %q: %w", name, m.sources[name], err)
+	}
+
+	// expand macros referenced by this macro
+	m.expanding = append(m.expanding, name)
+	defer func() {
+		m.expanding = m.expanding[:len(m.expanding)-1]
+	}()
+
+	p := m.patcher()
+	ast.Walk(&tree.Node, p)
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	return tree.Node, nil
+}
+
+func (m *macros) patcher() *macroPatcher {
+	return &macroPatcher{macros: m}
+}
+
+func (p *macroPatcher) Enter(_ *ast.Node) {}
+
+func (p *macroPatcher) Exit(node *ast.Node) {
+	n, ok := (*node).(*ast.IdentifierNode)
+	if !ok || p.err != nil {
+		return
+	}
+
+	if _, ok := p.macros.sources[n.Value]; !ok {
+		return
+	}
+
+	expanded, err := p.macros.expand(n.Value)
+	if err != nil {
+		p.err = err
+		return
+	}
+
+	ast.Patch(node, expanded)
+}
+
+// compile compiles an expression with the referenced macros expanded
+func (m *macros) compile(expression string, ops ...expr.Option) (*vm.Program, error) {
+	if len(m.sources) == 0 {
+		return expr.Compile(expression, ops...)
+	}
+
+	p := m.patcher()
+	program, err := expr.Compile(expression, append(ops, expr.Patch(p))...)
+	if p.err != nil {
+		return nil, p.err
+	} else if err != nil {
+		return nil, err
+	}
+
+	return program, nil
+}
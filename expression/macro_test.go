@@ -0,0 +1,97 @@
+package expression
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/l3uddz/tqm/config"
+)
+
+func TestCompileMacros(t *testing.T) {
+	tests := []struct {
+		name    string
+		macros  map[string]string
+		remove  string
+		torrent config.Torrent
+		want    bool
+		wantErr string
+	}{
+		{
+			name:    "expands macro",
+			macros:  map[string]string{"WellSeeded": "Ratio > 2.0"},
+			remove:  "WellSeeded",
+			torrent: config.Torrent{Ratio: 3},
+			want:    true,
+		},
+		{
+			name:    "expands nested macros",
+			macros:  map[string]string{"WellSeeded": "Ratio > 2.0", "Done": "WellSeeded && Seeds > 5"},
+			remove:  "Done",
+			torrent: config.Torrent{Ratio: 3, Seeds: 2},
+			want:    false,
+		},
+		{
+			name:    "combines macro with expression",
+			macros:  map[string]string{"Old": "SeedingDays >= 30"},
+			remove:  "Old || Label == \"tmp\"",
+			torrent: config.Torrent{SeedingDays: 1, Label: "tmp"},
+			want:    true,
+		},
+		{
+			name:    "rejects torrent field name",
+			macros:  map[string]string{"Ratio": "Seeds > 1"},
+			remove:  "Ratio",
+			wantErr: "name is already used by a torrent field",
+		},
+		{
+			name:    "rejects torrent function name",
+			macros:  map[string]string{"IsUnregistered": "Seeds > 1"},
+			remove:  "IsUnregistered()",
+			wantErr: "name is already used by a torrent function",
+		},
+		{
+			name:    "detects self reference",
+			macros:  map[string]string{"Loop": "Loop && Seeds > 1"},
+			remove:  "Loop",
+			wantErr: "reference cycle: Loop -> Loop",
+		},
+		{
+			name:    "detects indirect cycle",
+			macros:  map[string]string{"A": "B", "B": "A"},
+			remove:  "A",
+			wantErr: "reference cycle: B -> A -> B",
+		},
+		{
+			name:    "reports macro syntax error",
+			macros:  map[string]string{"Broken": "Ratio >"},
+			remove:  "Broken",
+			wantErr: `macro "Broken"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exp, err := Compile(&config.FilterConfiguration{
+				Macros: tt.macros,
+				Remove: []string{tt.remove},
+			})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Compile() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+
+			got, err := CheckTorrentSingleMatch(&tt.torrent, exp.Removes)
+			if err != nil {
+				t.Fatalf("CheckTorrentSingleMatch() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CheckTorrentSingleMatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -6,9 +6,20 @@ type Expressions struct {
 	Ignores []*vm.Program
 	Removes []*vm.Program
 	Labels  []*LabelExpression
+
+	// sources of the ignore and remove expressions (by index)
+	ignoreSources []string
+	removeSources []string
+
+	// functions called and identifiers referenced by the expressions
+	functions   map[string]bool
+	identifiers map[string]bool
 }
 
 type LabelExpression struct {
 	Name    string
 	Updates []*vm.Program
+
+	// source of the update expressions
+	rule string
 }
@@ -0,0 +1,60 @@
+package expression
+
+import (
+	"github.com/antonmedv/expr/ast"
+	"github.com/antonmedv/expr/parser"
+)
+
+type nameCollector struct {
+	functions   map[string]bool
+	identifiers map[string]bool
+}
+
+func (c *nameCollector) Enter(_ *ast.Node) {}
+
+func (c *nameCollector) Exit(node *ast.Node) {
+	switch n := (*node).(type) {
+	case *ast.FunctionNode:
+		c.functions[n.Name] = true
+	case *ast.IdentifierNode:
+		c.identifiers[n.Value] = true
+	}
+}
+
+func (e *Expressions) collectNames(expression string) {
+	tree, err := parser.Parse(expression)
+	if err != nil {
+		return
+	}
+
+	if e.functions == nil {
+		e.functions = make(map[string]bool)
+	}
+	if e.identifiers == nil {
+		e.identifiers = make(map[string]bool)
+	}
+
+	ast.Walk(&tree.Node, &nameCollector{functions: e.functions, identifiers: e.identifiers})
+}
+
+// UsesFunction returns whether any of the compiled expressions call one of the functions
+func (e *Expressions) UsesFunction(names ...string) bool {
+	for _, name := range names {
+		if e.functions[name] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UsesField returns whether any of the compiled expressions reference one of the torrent fields
+func (e *Expressions) UsesField(names ...string) bool {
+	for _, name := range names {
+		if e.identifiers[name] {
+			return true
+		}
+	}
+
+	return false
+}
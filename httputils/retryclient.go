@@ -1,6 +1,7 @@
 package httputils
 
 import (
+	"context"
 	"net/http"
 	"time"
 
@@ -12,8 +13,14 @@ import (
 )
 
 func NewRetryableHttpClient(timeout time.Duration, rl ratelimit.Limiter, log *logrus.Entry) *http.Client {
+	return NewRetryableHttpClientWithRetries(timeout, 10, rl, log)
+}
+
+// NewRetryableHttpClientWithRetries returns a client retrying failed requests up to the given retries
+func NewRetryableHttpClientWithRetries(timeout time.Duration, retries int, rl ratelimit.Limiter,
+	log *logrus.Entry) *http.Client {
 	retryClient := retryablehttp.NewClient()
-	retryClient.RetryMax = 10
+	retryClient.RetryMax = retries
 	retryClient.RetryWaitMin = 1 * time.Second
 	retryClient.RetryWaitMax = 10 * time.Second
 	retryClient.RequestLogHook = func(l retryablehttp.Logger, request *http.Request, i int) {
@@ -23,8 +30,8 @@ func NewRetryableHttpClient(timeout time.Duration, rl ratelimit.Limiter, log *lo
 		}
 
 		// rate limit
-		if rl != nil {
-			rl.Take()
+		if rl != nil && request != nil {
+			takeContext(request.Context(), rl)
 		}
 
 		// log
@@ -43,3 +50,18 @@ func NewRetryableHttpClient(timeout time.Duration, rl ratelimit.Limiter, log *lo
 	retryClient.Logger = nil
 	return retryClient.StandardClient()
 }
+
+// takeContext waits for the rate limiter, returning early once the context is done (the request then fails with the
+// context error)
+func takeContext(ctx context.Context, rl ratelimit.Limiter) {
+	done := make(chan struct{})
+	go func() {
+		rl.Take()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,42 @@
+package httputils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/ratelimit"
+)
+
+func TestRetryableHttpClientRateLimitContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	// one request per minute, the first request uses it up
+	client := NewRetryableHttpClient(time.Second, ratelimit.New(1, ratelimit.Per(time.Minute)), nil)
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	// the next request waits for the rate limit until its context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if resp, err := client.Do(req.WithContext(ctx)); err == nil {
+		resp.Body.Close()
+		t.Fatal("Do() succeeded, want context error")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Do() took %s, want it to return once the context is done", elapsed)
+	}
+}
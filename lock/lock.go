@@ -0,0 +1,98 @@
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrLocked is returned when the lock is still held by another process once the timeout expired
+	ErrLocked = errors.New("lock held by another process")
+
+	pollInterval = 500 * time.Millisecond
+)
+
+type Lock struct {
+	file *os.File
+}
+
+/* Public */
+
+// Acquire takes the exclusive lock of the file, waiting up to timeout for another process to release it
+func Acquire(path string, timeout time.Duration) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("create lock directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("open lock: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		locked, err := tryLock(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("lock: %w", err)
+		}
+
+		if locked {
+			break
+		}
+
+		if !time.Now().Before(deadline) {
+			pid := owner(f)
+			f.Close()
+			if pid > 0 {
+				return nil, fmt.Errorf("%w (pid %d)", ErrLocked, pid)
+			}
+			return nil, ErrLocked
+		}
+
+		time.Sleep(pollInterval)
+	}
+
+	// record the owner to ease troubleshooting
+	if err := f.Truncate(0); err == nil {
+		_, _ = f.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0)
+	}
+
+	return &Lock{file: f}, nil
+}
+
+// Release releases the lock, the lock is also released when the process exits
+func (l *Lock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+
+	defer func() {
+		l.file = nil
+	}()
+
+	if err := unlock(l.file); err != nil {
+		l.file.Close()
+		return fmt.Errorf("unlock: %w", err)
+	}
+
+	return l.file.Close()
+}
+
+/* Private */
+
+func owner(f *os.File) int {
+	b := make([]byte, 32)
+	n, _ := f.ReadAt(b, 0)
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b[:n])))
+	if err != nil {
+		return 0
+	}
+	return pid
+}
@@ -0,0 +1,84 @@
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func init() {
+	pollInterval = 10 * time.Millisecond
+}
+
+func TestAcquire(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "locks", "client.lock")
+
+	l, err := Acquire(path, 0)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	// the lock records its owner
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := fmt.Sprint(os.Getpid()); string(b) != want {
+		t.Errorf("Acquire() owner = %q, want %q", b, want)
+	}
+
+	// held locks time out
+	start := time.Now()
+	if _, err := Acquire(path, 50*time.Millisecond); !errors.Is(err, ErrLocked) {
+		t.Fatalf("Acquire() while held error = %v, want %v", err, ErrLocked)
+	} else if want := fmt.Sprintf("(pid %d)", os.Getpid()); err.Error() != ErrLocked.Error()+" "+want {
+		t.Errorf("Acquire() while held error = %q, want owner %s", err, want)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Acquire() returned after %s, before the timeout", elapsed)
+	}
+
+	// released locks can be taken again
+	if err := l.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if err := l.Release(); err != nil {
+		t.Fatalf("Release() twice error = %v", err)
+	}
+
+	l, err = Acquire(path, 0)
+	if err != nil {
+		t.Fatalf("Acquire() after release error = %v", err)
+	}
+	_ = l.Release()
+}
+
+func TestAcquireWaits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "client.lock")
+
+	held, err := Acquire(path, 0)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = held.Release()
+	}()
+
+	l, err := Acquire(path, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Acquire() waiting for release error = %v", err)
+	}
+	_ = l.Release()
+}
+
+func TestReleaseNil(t *testing.T) {
+	var l *Lock
+	if err := l.Release(); err != nil {
+		t.Errorf("Release() of nil lock error = %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lucperkins/rek"
+	"github.com/sirupsen/logrus"
+
+	"github.com/l3uddz/tqm/httputils"
+	"github.com/l3uddz/tqm/logger"
+)
+
+type Config struct {
+	Webhooks []string `validate:"url"`
+}
+
+type Notification struct {
+	Event   string            `json:"event"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+	Time    time.Time         `json:"time"`
+}
+
+var (
+	webhooks []string
+	client   *http.Client
+	log      *logrus.Entry
+	wg       sync.WaitGroup
+
+	// each request and the whole delivery (including retries) to a webhook are bound
+	requestTimeout = 10 * time.Second
+	sendTimeout    = 30 * time.Second
+)
+
+const sendRetries = 2
+
+/* Public */
+
+func Init(cfg Config) {
+	log = logger.GetLogger("notification")
+	webhooks = cfg.Webhooks
+	client = httputils.NewRetryableHttpClientWithRetries(requestTimeout, sendRetries, nil, log)
+}
+
+// Send posts the notification to the configured webhooks in the background, failures are logged
+func Send(event string, message string, fields map[string]string) {
+	n := Notification{
+		Event:   event,
+		Message: message,
+		Fields:  fields,
+		Time:    time.Now().UTC(),
+	}
+
+	for _, webhook := range webhooks {
+		wg.Add(1)
+		go func(webhook string) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+			defer cancel()
+
+			if err := send(ctx, webhook, n); err != nil {
+				log.WithError(err).Errorf("Failed sending %s notification", event)
+			}
+		}(webhook)
+	}
+}
+
+// Wait blocks until the notifications sent so far were delivered or failed
+func Wait() {
+	wg.Wait()
+}
+
+/* Private */
+
+func send(ctx context.Context, webhook string, n Notification) error {
+	resp, err := rek.Post(webhook, rek.Client(client), rek.Context(ctx), rek.Json(n))
+	if err != nil {
+		return fmt.Errorf("request webhook: %w", err)
+	}
+	defer resp.Body().Close()
+
+	if resp.StatusCode() < 200 || resp.StatusCode() > 299 {
+		return fmt.Errorf("validate webhook response: %s", resp.Status())
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package notification
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSend(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		delay     time.Duration
+		wantCalls int32
+	}{
+		{
+			name:      "delivered",
+			status:    http.StatusOK,
+			wantCalls: 1,
+		},
+		{
+			name:      "retried",
+			status:    http.StatusServiceUnavailable,
+			wantCalls: sendRetries + 1,
+		},
+		{
+			name:      "timed out",
+			status:    http.StatusOK,
+			delay:     time.Second,
+			wantCalls: 1,
+		},
+	}
+
+	requestTimeout = 200 * time.Millisecond
+	sendTimeout = 300 * time.Millisecond
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				time.Sleep(tt.delay)
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			Init(Config{Webhooks: []string{srv.URL}})
+
+			start := time.Now()
+			Send("test", "message", nil)
+			if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+				t.Errorf("Send() blocked for %s", elapsed)
+			}
+
+			Wait()
+			if elapsed := time.Since(start); elapsed > sendTimeout+100*time.Millisecond {
+				t.Errorf("Wait() took %s, want at most %s", elapsed, sendTimeout)
+			}
+			if got := atomic.LoadInt32(&calls); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
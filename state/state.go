@@ -0,0 +1,146 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/l3uddz/tqm/lock"
+)
+
+type ClientState struct {
+	LastRun      time.Time `json:"last_run"`
+	Unregistered int       `json:"unregistered"`
+
+	// torrents seen unregistered by consecutive runs (by hash)
+	UnregisteredTorrents map[string]*UnregisteredTorrent `json:"unregistered_torrents,omitempty"`
+
+	// unregistered or errored torrents per tracker
+	TrackerErrors map[string]int `json:"tracker_errors,omitempty"`
+}
+
+type UnregisteredTorrent struct {
+	Since time.Time `json:"since"`
+	Runs  int       `json:"runs"`
+}
+
+type fileState struct {
+	Clients map[string]*ClientState `json:"clients"`
+}
+
+var (
+	statePath string
+	state     = fileState{Clients: make(map[string]*ClientState)}
+	// clients used by this run, only their state is saved
+	clients = make(map[string]bool)
+	mtx     sync.Mutex
+
+	saveLockTimeout = 30 * time.Second
+)
+
+/* Public */
+
+// Init loads the state persisted by previous runs
+func Init(path string) error {
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	statePath = path
+	clients = make(map[string]bool)
+
+	s, err := load(path)
+	if err != nil {
+		return err
+	}
+
+	state = s
+	return nil
+}
+
+// Client returns the state of the client, which is persisted on Save
+func Client(name string) *ClientState {
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	cs, ok := state.Clients[name]
+	if !ok {
+		cs = new(ClientState)
+		state.Clients[name] = cs
+	}
+
+	clients[name] = true
+	return cs
+}
+
+// Save persists the state of the clients used by this run, the state of other clients is re-read so runs against
+// different clients do not overwrite each other's state
+func Save() error {
+	if statePath == "" {
+		return nil
+	}
+
+	// lock the state while merging, runs against different clients may save concurrently
+	l, err := lock.Acquire(statePath+".lock", saveLockTimeout)
+	if err != nil {
+		return fmt.Errorf("lock state: %w", err)
+	}
+	defer l.Release()
+
+	current, err := load(statePath)
+	if err != nil {
+		return err
+	}
+
+	mtx.Lock()
+	for name := range clients {
+		current.Clients[name] = state.Clients[name]
+	}
+	b, err := json.Marshal(current)
+	mtx.Unlock()
+	if err != nil {
+		return fmt.Errorf("encode state: %w", err)
+	}
+
+	// write to a temporary file before replacing the existing state
+	if err := os.MkdirAll(filepath.Dir(statePath), os.ModePerm); err != nil {
+		return fmt.Errorf("create state directory: %w", err)
+	}
+
+	tmp := statePath + ".tmp"
+	if err := os.WriteFile(tmp, b, 0600); err != nil {
+		return fmt.Errorf("write state: %w", err)
+	}
+
+	if err := os.Rename(tmp, statePath); err != nil {
+		return fmt.Errorf("replace state: %w", err)
+	}
+
+	return nil
+}
+
+/* Private */
+
+func load(path string) (fileState, error) {
+	s := fileState{Clients: make(map[string]*ClientState)}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	} else if err != nil {
+		return s, fmt.Errorf("read state: %w", err)
+	}
+
+	if err := json.Unmarshal(b, &s); err != nil {
+		return s, fmt.Errorf("decode state: %w", err)
+	}
+
+	if s.Clients == nil {
+		s.Clients = make(map[string]*ClientState)
+	}
+
+	return s, nil
+}
@@ -0,0 +1,49 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveMergesClients(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	// state saved by a previous run
+	if err := os.WriteFile(path, []byte(`{"clients":{"a":{"unregistered":1},"b":{"unregistered":2}}}`),
+		0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Init(path); err != nil {
+		t.Fatal(err)
+	}
+	Client("a").Unregistered = 10
+
+	// state saved by a concurrent run against another client
+	if err := os.WriteFile(path, []byte(`{"clients":{"a":{"unregistered":1},"b":{"unregistered":20},`+
+		`"c":{"unregistered":30}}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]int{"a": 10, "b": 20, "c": 30}
+	for name, unregistered := range want {
+		cs, ok := saved.Clients[name]
+		if !ok {
+			t.Errorf("client %q: not saved", name)
+			continue
+		}
+		if cs.Unregistered != unregistered {
+			t.Errorf("client %q: unregistered = %d, want %d", name, cs.Unregistered, unregistered)
+		}
+	}
+}
@@ -86,6 +86,21 @@ func (t *TorrentFileMap) RemovePath(path string) {
 	delete(t.torrentFileMap, path)
 }
 
+func (t *TorrentFileMap) Clone() *TorrentFileMap {
+	c := &TorrentFileMap{
+		torrentFileMap: make(map[string]map[string]config.Torrent, len(t.torrentFileMap)),
+	}
+
+	for f, torrents := range t.torrentFileMap {
+		c.torrentFileMap[f] = make(map[string]config.Torrent, len(torrents))
+		for h, torrent := range torrents {
+			c.torrentFileMap[f][h] = torrent
+		}
+	}
+
+	return c
+}
+
 func (t *TorrentFileMap) Length() int {
 	return len(t.torrentFileMap)
 }
@@ -0,0 +1,33 @@
+package tracker
+
+import (
+	"strings"
+)
+
+type AliasConfig struct {
+	Name  string   `validate:"required"`
+	Hosts []string `validate:"required"`
+}
+
+var (
+	aliases []AliasConfig
+)
+
+// CanonicalName returns the configured alias for the announce host, or the fallback when there is none
+func CanonicalName(host string, fallback string) string {
+	host = strings.ToLower(host)
+	if host == "" {
+		return fallback
+	}
+
+	for _, alias := range aliases {
+		for _, h := range alias.Hosts {
+			h = strings.ToLower(h)
+			if host == h || strings.HasSuffix(host, "."+h) {
+				return alias.Name
+			}
+		}
+	}
+
+	return fallback
+}
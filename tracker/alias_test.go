@@ -0,0 +1,34 @@
+package tracker
+
+import (
+	"testing"
+)
+
+func TestCanonicalName(t *testing.T) {
+	aliases = []AliasConfig{
+		{Name: "BTN", Hosts: []string{"landof.tv", "broadcasthe.net"}},
+		{Name: "RED", Hosts: []string{"Flacsfor.me"}},
+	}
+	defer func() { aliases = nil }()
+
+	tests := []struct {
+		host     string
+		fallback string
+		want     string
+	}{
+		{host: "landof.tv", fallback: "landof.tv", want: "BTN"},
+		{host: "tracker.landof.tv", fallback: "landof.tv", want: "BTN"},
+		{host: "FLACSFOR.ME", fallback: "flacsfor.me", want: "RED"},
+		{host: "notlandof.tv", fallback: "notlandof.tv", want: "notlandof.tv"},
+		{host: "landof.tv.example.com", fallback: "example.com", want: "example.com"},
+		{host: "", fallback: "name", want: "name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if got := CanonicalName(tt.host, tt.fallback); got != tt.want {
+				t.Errorf("CanonicalName(%q, %q) = %q, want %q", tt.host, tt.fallback, got, tt.want)
+			}
+		})
+	}
+}
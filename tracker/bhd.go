@@ -1,22 +1,24 @@
 package tracker
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/lucperkins/rek"
 	"github.com/sirupsen/logrus"
-	"go.uber.org/ratelimit"
 
 	"github.com/l3uddz/tqm/httputils"
 	"github.com/l3uddz/tqm/logger"
 )
 
 type BHDConfig struct {
-	Key string `koanf:"api_key"`
+	Key          string  `koanf:"api_key"`
+	MinSeedHours float64 `koanf:"min_seed_hours"`
+
+	RateLimitConfig `koanf:",squash"`
 }
 
 type BHD struct {
@@ -29,7 +31,7 @@ func NewBHD(c BHDConfig) *BHD {
 	l := logger.GetLogger("bhd-api")
 	return &BHD{
 		cfg:  c,
-		http: httputils.NewRetryableHttpClient(15*time.Second, ratelimit.New(1, ratelimit.WithoutSlack), l),
+		http: httputils.NewRetryableHttpClient(15*time.Second, c.limiter(1, time.Second), l),
 		log:  l,
 	}
 }
@@ -38,28 +40,78 @@ func (c *BHD) Name() string {
 	return "BHD"
 }
 
+func (c *BHD) Hosts() []string {
+	return []string{"beyond-hd.me"}
+}
+
 func (c *BHD) Check(host string) bool {
-	return strings.Contains(host, "beyond-hd.me")
+	return matchesHost(host, c.Hosts())
+}
+
+func (c *BHD) MinSeedHours() float64 {
+	return c.cfg.MinSeedHours
+}
+
+func (c *BHD) IsUnregistered(ctx context.Context, torrent *Torrent) (error, bool) {
+	r, err := c.Search(ctx, torrent)
+	if err != nil {
+		return err, false
+	}
+
+	return nil, r.Unregistered
+}
+
+func (c *BHD) GetMetadata(ctx context.Context, torrent *Torrent) (*Metadata, error) {
+	r, err := c.Search(ctx, torrent)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Metadata, nil
+}
+
+func (c *BHD) Search(ctx context.Context, torrent *Torrent) (*SearchResult, error) {
+	b, err := c.search(ctx, torrent)
+	if err != nil {
+		return nil, err
+	} else if b.TotalResults < 1 || len(b.Results) < 1 {
+		// torrent not found
+		return &SearchResult{Unregistered: b.TotalResults < 1}, nil
+	}
+
+	r := b.Results[0]
+	return &SearchResult{
+		Metadata: &Metadata{
+			Seeders:   r.Seeders,
+			Leechers:  r.Leechers,
+			Snatched:  r.TimesCompleted,
+			Freeleech: r.Freeleech == 1,
+		},
+	}, nil
+}
+
+type bhdResponse struct {
+	StatusCode int `json:"status_code"`
+	Page       int `json:"page"`
+	Results    []struct {
+		Name           string `json:"name"`
+		InfoHash       string `json:"info_hash"`
+		Seeders        int64  `json:"seeders"`
+		Leechers       int64  `json:"leechers"`
+		TimesCompleted int64  `json:"times_completed"`
+		Freeleech      int    `json:"freeleech"`
+	} `json:"results"`
+	TotalPages   int  `json:"total_pages"`
+	TotalResults int  `json:"total_results"`
+	Success      bool `json:"success"`
 }
 
-func (c *BHD) IsUnregistered(torrent *Torrent) (error, bool) {
+func (c *BHD) search(ctx context.Context, torrent *Torrent) (*bhdResponse, error) {
 	type Request struct {
 		Hash   string `json:"info_hash"`
 		Action string `json:"action"`
 	}
 
-	type Response struct {
-		StatusCode int `json:"status_code"`
-		Page       int `json:"page"`
-		Results    []struct {
-			Name     string `json:"name"`
-			InfoHash string `json:"info_hash"`
-		} `json:"results"`
-		TotalPages   int  `json:"total_pages"`
-		TotalResults int  `json:"total_results"`
-		Success      bool `json:"success"`
-	}
-
 	// prepare request
 	url := httputils.Join("https://beyond-hd.me/api/torrents", c.cfg.Key)
 	payload := &Request{
@@ -68,10 +120,10 @@ func (c *BHD) IsUnregistered(torrent *Torrent) (error, bool) {
 	}
 
 	// send request
-	resp, err := rek.Post(url, rek.Client(c.http), rek.Json(payload))
+	resp, err := rek.Post(url, rek.Client(c.http), rek.Context(ctx), rek.Json(payload))
 	if err != nil {
 		c.log.WithError(err).Errorf("Failed searching for %s (hash: %s)", torrent.Name, torrent.Hash)
-		return fmt.Errorf("bhd: request search: %w", err), false
+		return nil, fmt.Errorf("bhd: request search: %w", err)
 	}
 	defer resp.Body().Close()
 
@@ -79,16 +131,16 @@ func (c *BHD) IsUnregistered(torrent *Torrent) (error, bool) {
 	if resp.StatusCode() != 200 {
 		c.log.WithError(err).Errorf("Failed validating search response for %s (hash: %s), response: %s",
 			torrent.Name, torrent.Hash, resp.Status())
-		return fmt.Errorf("bhd: validate search response: %s", resp.Status()), false
+		return nil, fmt.Errorf("bhd: validate search response: %s", resp.Status())
 	}
 
 	// decode response
-	b := new(Response)
+	b := new(bhdResponse)
 	if err := json.NewDecoder(resp.Body()).Decode(b); err != nil {
 		c.log.WithError(err).Errorf("Failed decoding search response for %s (hash: %s)",
 			torrent.Name, torrent.Hash)
-		return fmt.Errorf("bhd: decode search response: %w", err), false
+		return nil, fmt.Errorf("bhd: decode search response: %w", err)
 	}
 
-	return nil, b.TotalResults < 1
+	return b, nil
 }
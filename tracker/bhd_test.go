@@ -0,0 +1,89 @@
+package tracker
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestBHDSearch(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           int
+		body             string
+		wantUnregistered bool
+		wantMetadata     *Metadata
+		wantErr          bool
+	}{
+		{name: "not found", status: 200, body: `{"total_results":0,"results":[],"success":true}`,
+			wantUnregistered: true},
+		{name: "found", status: 200,
+			body:         `{"total_results":1,"results":[{"seeders":3,"leechers":2,"times_completed":7,"freeleech":1}]}`,
+			wantMetadata: &Metadata{Seeders: 3, Leechers: 2, Snatched: 7, Freeleech: true}},
+		{name: "server error", status: 500, body: ``, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewBHD(BHDConfig{Key: "key", MinSeedHours: 120})
+			c.http = &http.Client{Transport: testTransport{url: newTestServer(t, tt.status, tt.body)}}
+
+			r, err := c.Search(context.Background(), &Torrent{Hash: "abc"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Search() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil {
+				return
+			}
+
+			if r.Unregistered != tt.wantUnregistered {
+				t.Errorf("Search() unregistered = %v, want %v", r.Unregistered, tt.wantUnregistered)
+			}
+			if (r.Metadata == nil) != (tt.wantMetadata == nil) ||
+				(r.Metadata != nil && *r.Metadata != *tt.wantMetadata) {
+				t.Errorf("Search() metadata = %+v, want %+v", r.Metadata, tt.wantMetadata)
+			}
+		})
+	}
+}
+
+func TestPTPSearch(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           int
+		body             string
+		wantUnregistered bool
+		wantMetadata     *Metadata
+		wantErr          bool
+	}{
+		{name: "unregistered", status: 200, body: `{"Result":"ERROR","ResultDetails":"Unregistered Torrent"}`,
+			wantUnregistered: true},
+		{name: "other error", status: 200, body: `{"Result":"ERROR","ResultDetails":"Rate limited"}`},
+		{name: "found", status: 200, body: `{"Torrents":[{"InfoHash":"OTHER","Seeders":"1"},` +
+			`{"InfoHash":"ABC","Seeders":"8","Leechers":"0","Snatched":"20","FreeleechType":"Freeleech"}]}`,
+			wantMetadata: &Metadata{Seeders: 8, Snatched: 20, Freeleech: true}},
+		{name: "not in group", status: 200, body: `{"Torrents":[{"InfoHash":"OTHER","Seeders":"1"}]}`},
+		{name: "unauthorized", status: 401, body: ``, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewPTP(PTPConfig{User: "user", Key: "key"})
+			c.http = &http.Client{Transport: testTransport{url: newTestServer(t, tt.status, tt.body)}}
+
+			r, err := c.Search(context.Background(), &Torrent{Hash: "abc"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Search() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil {
+				return
+			}
+
+			if r.Unregistered != tt.wantUnregistered {
+				t.Errorf("Search() unregistered = %v, want %v", r.Unregistered, tt.wantUnregistered)
+			}
+			if (r.Metadata == nil) != (tt.wantMetadata == nil) ||
+				(r.Metadata != nil && *r.Metadata != *tt.wantMetadata) {
+				t.Errorf("Search() metadata = %+v, want %+v", r.Metadata, tt.wantMetadata)
+			}
+		})
+	}
+}
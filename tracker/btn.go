@@ -0,0 +1,122 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lucperkins/rek"
+	"github.com/sirupsen/logrus"
+
+	"github.com/l3uddz/tqm/httputils"
+	"github.com/l3uddz/tqm/logger"
+)
+
+type BTNConfig struct {
+	Key string `koanf:"api_key"`
+
+	RateLimitConfig `koanf:",squash"`
+}
+
+type BTN struct {
+	cfg  BTNConfig
+	http *http.Client
+	log  *logrus.Entry
+}
+
+func NewBTN(c BTNConfig) *BTN {
+	l := logger.GetLogger("btn-api")
+	return &BTN{
+		cfg: c,
+		// btn allows 150 api calls per hour
+		http: httputils.NewRetryableHttpClient(15*time.Second, c.limiter(150, time.Hour), l),
+		log:  l,
+	}
+}
+
+func (c *BTN) Name() string {
+	return "BTN"
+}
+
+func (c *BTN) Hosts() []string {
+	// landof.tv is the announce host
+	return []string{"landof.tv", "broadcasthe.net"}
+}
+
+func (c *BTN) Check(host string) bool {
+	return matchesHost(host, c.Hosts())
+}
+
+func (c *BTN) IsUnregistered(ctx context.Context, torrent *Torrent) (error, bool) {
+	type Request struct {
+		JsonRPC string        `json:"jsonrpc"`
+		Id      int           `json:"id"`
+		Method  string        `json:"method"`
+		Params  []interface{} `json:"params"`
+	}
+
+	type Response struct {
+		Result *struct {
+			Results  json.Number     `json:"results"`
+			Torrents json.RawMessage `json:"torrents"`
+		} `json:"result"`
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+
+	// prepare request
+	payload := &Request{
+		JsonRPC: "2.0",
+		Id:      1,
+		Method:  "getTorrents",
+		Params: []interface{}{
+			c.cfg.Key,
+			map[string]string{"hash": strings.ToUpper(torrent.Hash)},
+			1,
+			0,
+		},
+	}
+
+	// send request
+	resp, err := rek.Post("https://api.broadcasthe.net/", rek.Client(c.http), rek.Context(ctx), rek.Json(payload))
+	if err != nil {
+		c.log.WithError(err).Errorf("Failed searching for %s (hash: %s)", torrent.Name, torrent.Hash)
+		return fmt.Errorf("btn: request search: %w", err), false
+	}
+	defer resp.Body().Close()
+
+	// validate response
+	if resp.StatusCode() != 200 {
+		c.log.WithError(err).Errorf("Failed validating search response for %s (hash: %s), response: %s",
+			torrent.Name, torrent.Hash, resp.Status())
+		return fmt.Errorf("btn: validate search response: %s", resp.Status()), false
+	}
+
+	// decode response
+	b := new(Response)
+	if err := json.NewDecoder(resp.Body()).Decode(b); err != nil {
+		c.log.WithError(err).Errorf("Failed decoding search response for %s (hash: %s)",
+			torrent.Name, torrent.Hash)
+		return fmt.Errorf("btn: decode search response: %w", err), false
+	}
+
+	if b.Error != nil {
+		c.log.Errorf("Failed searching for %s (hash: %s), response: %d: %s", torrent.Name, torrent.Hash,
+			b.Error.Code, b.Error.Message)
+		return fmt.Errorf("btn: search response: %d: %s", b.Error.Code, b.Error.Message), false
+	} else if b.Result == nil {
+		return fmt.Errorf("btn: search response: no result"), false
+	}
+
+	results, err := b.Result.Results.Int64()
+	if err != nil {
+		return fmt.Errorf("btn: parse search results: %w", err), false
+	}
+
+	return nil, results < 1
+}
@@ -0,0 +1,273 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/l3uddz/tqm/lock"
+)
+
+type CacheConfig struct {
+	PositiveTTL time.Duration `koanf:"positive_ttl" validate:"duration"`
+	NegativeTTL time.Duration `koanf:"negative_ttl" validate:"duration"`
+	Path        string        `koanf:"path"`
+}
+
+type cacheEntry struct {
+	Unregistered bool      `json:"unregistered"`
+	Metadata     *Metadata `json:"metadata,omitempty"`
+	Expires      time.Time `json:"expires"`
+
+	// kept for the current run only, not persisted
+	runOnly bool
+}
+
+type cache struct {
+	cfg     CacheConfig
+	entries map[string]cacheEntry
+	// entries set by this run, only they are saved
+	updated map[string]bool
+	hits    int
+	misses  int
+	mtx     sync.Mutex
+}
+
+var (
+	ErrMetadataUnsupported = errors.New("tracker does not support metadata")
+
+	cacheLockTimeout = 30 * time.Second
+)
+
+type cachedTracker struct {
+	Interface
+	cache *cache
+}
+
+/* Cache */
+
+func newCache(cfg CacheConfig) (*cache, error) {
+	// set default ttls (unregistered results are only kept for the current run unless a positive ttl is set, so a
+	// transient api failure does not count as unregistered across runs)
+	if cfg.NegativeTTL == 0 {
+		cfg.NegativeTTL = 1 * time.Hour
+	}
+
+	c := &cache{
+		cfg:     cfg,
+		entries: make(map[string]cacheEntry),
+		updated: make(map[string]bool),
+	}
+
+	if cfg.Path == "" {
+		return c, nil
+	}
+
+	// load persisted entries
+	entries, err := loadCacheEntries(cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	c.entries = entries
+	return c, nil
+}
+
+// loadCacheEntries returns the unexpired entries persisted to the path
+func loadCacheEntries(path string) (map[string]cacheEntry, error) {
+	entries := make(map[string]cacheEntry)
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return entries, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read cache: %w", err)
+	}
+
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return nil, fmt.Errorf("decode cache: %w", err)
+	}
+
+	// drop expired entries
+	now := time.Now()
+	for k, e := range entries {
+		if now.After(e.Expires) {
+			delete(entries, k)
+		}
+	}
+
+	return entries, nil
+}
+
+func cacheKey(kind string, tracker string, hash string) string {
+	return kind + "|" + tracker + "|" + strings.ToLower(hash)
+}
+
+func (c *cache) get(key string) (*cacheEntry, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok || (!e.runOnly && time.Now().After(e.Expires)) {
+		c.misses++
+		return nil, false
+	}
+
+	c.hits++
+	return &e, true
+}
+
+// set caches the entry for the ttl, or for the current run only when the ttl is not set
+func (c *cache) set(key string, e cacheEntry, ttl time.Duration) {
+	e.Expires = time.Now().Add(ttl)
+	e.runOnly = ttl <= 0
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.entries[key] = e
+	c.updated[key] = true
+}
+
+// save persists the entries looked up by this run, the other entries are re-read so concurrent runs do not
+// overwrite each other's entries
+func (c *cache) save() error {
+	if c.cfg.Path == "" {
+		return nil
+	}
+
+	// lock the cache while merging, runs against different clients may save concurrently
+	l, err := lock.Acquire(c.cfg.Path+".lock", cacheLockTimeout)
+	if err != nil {
+		return fmt.Errorf("lock cache: %w", err)
+	}
+	defer l.Release()
+
+	entries, err := loadCacheEntries(c.cfg.Path)
+	if err != nil {
+		return err
+	}
+
+	c.mtx.Lock()
+	for k := range c.updated {
+		if e := c.entries[k]; !e.runOnly {
+			entries[k] = e
+		}
+	}
+	b, err := json.Marshal(entries)
+	c.mtx.Unlock()
+	if err != nil {
+		return fmt.Errorf("encode cache: %w", err)
+	}
+
+	// write to a temporary file before replacing the existing cache
+	if err := os.MkdirAll(filepath.Dir(c.cfg.Path), os.ModePerm); err != nil {
+		return fmt.Errorf("create cache directory: %w", err)
+	}
+
+	tmp := c.cfg.Path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0600); err != nil {
+		return fmt.Errorf("write cache: %w", err)
+	}
+
+	if err := os.Rename(tmp, c.cfg.Path); err != nil {
+		return fmt.Errorf("replace cache: %w", err)
+	}
+
+	return nil
+}
+
+/* Tracker */
+
+func (t *cachedTracker) MinSeedHours() float64 {
+	if si, ok := t.Interface.(SeedTimeInterface); ok {
+		return si.MinSeedHours()
+	}
+
+	return 0
+}
+
+func (t *cachedTracker) IsUnregistered(ctx context.Context, torrent *Torrent) (error, bool) {
+	if _, ok := t.Interface.(SearchInterface); ok {
+		r, err := t.search(ctx, torrent)
+		if err != nil {
+			return err, false
+		}
+		return nil, r.Unregistered
+	}
+
+	key := cacheKey("unregistered", t.Name(), torrent.Hash)
+	if e, ok := t.cache.get(key); ok {
+		return nil, e.Unregistered
+	}
+
+	err, ur := t.Interface.IsUnregistered(ctx, torrent)
+	if err != nil {
+		return err, ur
+	}
+
+	ttl := t.cache.cfg.NegativeTTL
+	if ur {
+		ttl = t.cache.cfg.PositiveTTL
+	}
+
+	t.cache.set(key, cacheEntry{Unregistered: ur}, ttl)
+	return nil, ur
+}
+
+func (t *cachedTracker) GetMetadata(ctx context.Context, torrent *Torrent) (*Metadata, error) {
+	if _, ok := t.Interface.(SearchInterface); ok {
+		r, err := t.search(ctx, torrent)
+		if err != nil {
+			return nil, err
+		}
+		return r.Metadata, nil
+	}
+
+	mi, ok := t.Interface.(MetadataInterface)
+	if !ok {
+		return nil, ErrMetadataUnsupported
+	}
+
+	key := cacheKey("metadata", t.Name(), torrent.Hash)
+	if e, ok := t.cache.get(key); ok {
+		return e.Metadata, nil
+	}
+
+	md, err := mi.GetMetadata(ctx, torrent)
+	if err != nil {
+		return nil, err
+	}
+
+	// metadata changes frequently, so always use the negative ttl
+	t.cache.set(key, cacheEntry{Metadata: md}, t.cache.cfg.NegativeTTL)
+	return md, nil
+}
+
+// search looks up the unregistered status and metadata of the torrent with a single search, caching them together
+func (t *cachedTracker) search(ctx context.Context, torrent *Torrent) (*SearchResult, error) {
+	key := cacheKey("search", t.Name(), torrent.Hash)
+	if e, ok := t.cache.get(key); ok {
+		return &SearchResult{Unregistered: e.Unregistered, Metadata: e.Metadata}, nil
+	}
+
+	r, err := t.Interface.(SearchInterface).Search(ctx, torrent)
+	if err != nil {
+		return nil, err
+	}
+
+	// metadata changes frequently, so registered results use the negative ttl
+	ttl := t.cache.cfg.NegativeTTL
+	if r.Unregistered {
+		ttl = t.cache.cfg.PositiveTTL
+	}
+
+	t.cache.set(key, cacheEntry{Unregistered: r.Unregistered, Metadata: r.Metadata}, ttl)
+	return r, nil
+}
@@ -0,0 +1,174 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type fakeTracker struct {
+	unregistered bool
+	calls        int
+}
+
+func (f *fakeTracker) Name() string { return "fake" }
+
+func (f *fakeTracker) Hosts() []string { return []string{"fake.example"} }
+
+func (f *fakeTracker) Check(host string) bool { return matchesHost(host, f.Hosts()) }
+
+func (f *fakeTracker) IsUnregistered(_ context.Context, _ *Torrent) (error, bool) {
+	f.calls++
+	return nil, f.unregistered
+}
+
+func TestCachedTrackerTTLs(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          CacheConfig
+		unregistered bool
+		wantPersist  bool
+	}{
+		{name: "registered", unregistered: false, wantPersist: true},
+		{name: "unregistered without positive ttl", unregistered: true, wantPersist: false},
+		{name: "unregistered with positive ttl", cfg: CacheConfig{PositiveTTL: time.Hour}, unregistered: true,
+			wantPersist: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Path = filepath.Join(t.TempDir(), "cache.json")
+			c, err := newCache(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ft := &fakeTracker{unregistered: tt.unregistered}
+			ct := &cachedTracker{Interface: ft, cache: c}
+			torrent := &Torrent{Hash: "ABC"}
+
+			// the second lookup of the run is always cached
+			for i := 0; i < 2; i++ {
+				if err, ur := ct.IsUnregistered(context.Background(), torrent); err != nil || ur != tt.unregistered {
+					t.Fatalf("IsUnregistered() = %v, %v, want %v", err, ur, tt.unregistered)
+				}
+			}
+			if ft.calls != 1 {
+				t.Errorf("tracker lookups = %d, want 1", ft.calls)
+			}
+			if hits, misses := c.hits, c.misses; hits != 1 || misses != 1 {
+				t.Errorf("hits, misses = %d, %d, want 1, 1", hits, misses)
+			}
+
+			if err := c.save(); err != nil {
+				t.Fatal(err)
+			}
+
+			b, err := os.ReadFile(tt.cfg.Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			entries := make(map[string]cacheEntry)
+			if err := json.Unmarshal(b, &entries); err != nil {
+				t.Fatal(err)
+			}
+			if persisted := len(entries) == 1; persisted != tt.wantPersist {
+				t.Errorf("persisted = %v, want %v", persisted, tt.wantPersist)
+			}
+		})
+	}
+}
+
+type fakeSearchTracker struct {
+	fakeTracker
+	result SearchResult
+}
+
+func (f *fakeSearchTracker) Search(_ context.Context, _ *Torrent) (*SearchResult, error) {
+	f.calls++
+	r := f.result
+	return &r, nil
+}
+
+func TestCachedTrackerSearch(t *testing.T) {
+	tests := []struct {
+		name   string
+		result SearchResult
+	}{
+		{name: "registered", result: SearchResult{Metadata: &Metadata{Seeders: 5, Freeleech: true}}},
+		{name: "unregistered", result: SearchResult{Unregistered: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := newCache(CacheConfig{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ft := &fakeSearchTracker{result: tt.result}
+			ct := &cachedTracker{Interface: ft, cache: c}
+			torrent := &Torrent{Hash: "abc"}
+
+			err, ur := ct.IsUnregistered(context.Background(), torrent)
+			if err != nil || ur != tt.result.Unregistered {
+				t.Fatalf("IsUnregistered() = %v, %v, want %v", err, ur, tt.result.Unregistered)
+			}
+
+			md, err := ct.GetMetadata(context.Background(), torrent)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (md == nil) != (tt.result.Metadata == nil) || (md != nil && *md != *tt.result.Metadata) {
+				t.Errorf("GetMetadata() = %+v, want %+v", md, tt.result.Metadata)
+			}
+
+			// both lookups are answered by a single search
+			if ft.calls != 1 {
+				t.Errorf("tracker searches = %d, want 1", ft.calls)
+			}
+		})
+	}
+}
+
+func TestCacheSaveMergesEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	c, err := newCache(CacheConfig{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.set("unregistered|a|1", cacheEntry{}, time.Hour)
+	c.set("unregistered|a|2", cacheEntry{Unregistered: true}, 0)
+
+	// entries saved by a concurrent run
+	other, err := newCache(CacheConfig{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.set("unregistered|b|1", cacheEntry{}, time.Hour)
+	if err := other.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := loadCacheEntries(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, k := range []string{"unregistered|a|1", "unregistered|b|1"} {
+		if _, ok := entries[k]; !ok {
+			t.Errorf("entry %q: not saved", k)
+		}
+	}
+	if _, ok := entries["unregistered|a|2"]; ok {
+		t.Errorf("entry %q: run only entry saved", "unregistered|a|2")
+	}
+}
@@ -0,0 +1,145 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lucperkins/rek"
+	"github.com/sirupsen/logrus"
+
+	"github.com/l3uddz/tqm/httputils"
+	"github.com/l3uddz/tqm/logger"
+)
+
+var (
+	// gazelle api error returned for torrents which no longer exist
+	gazelleUnregisteredErrors = []string{
+		"bad hash parameter",
+	}
+)
+
+type GazelleConfig struct {
+	Url     string   `koanf:"base_url" validate:"url"`
+	Key     string   `koanf:"api_key"`
+	Domains []string `koanf:"domains"`
+
+	// exact api errors of the tracker for deleted torrents, besides the default
+	UnregisteredErrors []string `koanf:"unregistered_errors"`
+
+	RateLimitConfig `koanf:",squash"`
+}
+
+type Gazelle struct {
+	name    string
+	cfg     GazelleConfig
+	http    *http.Client
+	headers map[string]string
+	log     *logrus.Entry
+}
+
+func NewGazelle(name string, c GazelleConfig) *Gazelle {
+	l := logger.GetLogger(name + "-api")
+
+	// default to the host of the base url when no domains were set
+	if len(c.Domains) == 0 {
+		if u, err := url.Parse(c.Url); err == nil && u.Hostname() != "" {
+			c.Domains = []string{u.Hostname()}
+		}
+	}
+
+	return &Gazelle{
+		name: name,
+		cfg:  c,
+		// gazelle trackers publish their limits as requests per 10 seconds
+		http: httputils.NewRetryableHttpClient(15*time.Second, c.limiter(5, 10*time.Second), l),
+		headers: map[string]string{
+			"Authorization": c.Key,
+		},
+		log: l,
+	}
+}
+
+func (c *Gazelle) Name() string {
+	return c.name
+}
+
+func (c *Gazelle) Hosts() []string {
+	return c.cfg.Domains
+}
+
+func (c *Gazelle) Check(host string) bool {
+	return matchesHost(host, c.Hosts())
+}
+
+func (c *Gazelle) IsUnregistered(ctx context.Context, torrent *Torrent) (error, bool) {
+	type Response struct {
+		Status   string          `json:"status"`
+		Error    string          `json:"error"`
+		Response json.RawMessage `json:"response"`
+	}
+
+	// prepare request
+	reqURL, err := httputils.WithQuery(httputils.Join(c.cfg.Url, "ajax.php"), url.Values{
+		"action": []string{"torrent"},
+		"hash":   []string{strings.ToUpper(torrent.Hash)},
+	})
+	if err != nil {
+		return fmt.Errorf("%s: url parse: %w", c.name, err), false
+	}
+
+	// send request
+	resp, err := rek.Get(reqURL, rek.Client(c.http), rek.Context(ctx), rek.Headers(c.headers))
+	if err != nil {
+		c.log.WithError(err).Errorf("Failed searching for %s (hash: %s)", torrent.Name, torrent.Hash)
+		return fmt.Errorf("%s: request search: %w", c.name, err), false
+	}
+	defer resp.Body().Close()
+
+	// validate response
+	if resp.StatusCode() != 200 {
+		c.log.WithError(err).Errorf("Failed validating search response for %s (hash: %s), response: %s",
+			torrent.Name, torrent.Hash, resp.Status())
+		return fmt.Errorf("%s: validate search response: %s", c.name, resp.Status()), false
+	}
+
+	// decode response
+	b := new(Response)
+	if err := json.NewDecoder(resp.Body()).Decode(b); err != nil {
+		c.log.WithError(err).Errorf("Failed decoding search response for %s (hash: %s)",
+			torrent.Name, torrent.Hash)
+		return fmt.Errorf("%s: decode search response: %w", c.name, err), false
+	}
+
+	if b.Status == "success" {
+		return nil, false
+	} else if b.Status == "failure" && c.isUnregisteredError(b.Error) {
+		return nil, true
+	}
+
+	c.log.Errorf("Failed searching for %s (hash: %s), response: %s: %s", torrent.Name, torrent.Hash,
+		b.Status, b.Error)
+	return fmt.Errorf("%s: search response: %s: %s", c.name, b.Status, b.Error), false
+}
+
+// isUnregisteredError returns whether the api error is exactly one of the errors returned for deleted torrents,
+// other errors (e.g. bad parameters or authentication failures) must not mark torrents unregistered
+func (c *Gazelle) isUnregisteredError(apiErr string) bool {
+	apiErr = strings.TrimSpace(apiErr)
+	for _, v := range gazelleUnregisteredErrors {
+		if strings.EqualFold(apiErr, v) {
+			return true
+		}
+	}
+	for _, v := range c.cfg.UnregisteredErrors {
+		if strings.EqualFold(apiErr, strings.TrimSpace(v)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,52 @@
+package tracker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGazelleIsUnregistered(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           int
+		body             string
+		errors           []string
+		wantUnregistered bool
+		wantErr          bool
+	}{
+		{name: "success", status: 200, body: `{"status":"success","response":{}}`},
+		{name: "bad hash parameter", status: 200, body: `{"status":"failure","error":"bad hash parameter"}`,
+			wantUnregistered: true},
+		{name: "bad hash parameter case", status: 200, body: `{"status":"failure","error":"Bad Hash Parameter"}`,
+			wantUnregistered: true},
+		{name: "bad parameters", status: 200, body: `{"status":"failure","error":"bad parameters"}`, wantErr: true},
+		{name: "deleted substring", status: 200, body: `{"status":"failure","error":"api key deleted"}`,
+			wantErr: true},
+		{name: "configured deletion error", status: 200, body: `{"status":"failure","error":"torrent was deleted"}`,
+			errors: []string{"Torrent was deleted"}, wantUnregistered: true},
+		{name: "bad request", status: 400, body: `{"status":"failure","error":"bad hash parameter"}`, wantErr: true},
+		{name: "unauthorized", status: 401, body: `{"status":"failure","error":"bad credentials"}`, wantErr: true},
+		{name: "invalid json", status: 200, body: `<html>`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			c := NewGazelle("test", GazelleConfig{Url: srv.URL, Key: "key", UnregisteredErrors: tt.errors})
+			err, unregistered := c.IsUnregistered(context.Background(), &Torrent{Hash: "abc", Name: "test"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("IsUnregistered() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if unregistered != tt.wantUnregistered {
+				t.Errorf("IsUnregistered() = %v, want %v", unregistered, tt.wantUnregistered)
+			}
+		})
+	}
+}
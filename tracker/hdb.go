@@ -0,0 +1,106 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/lucperkins/rek"
+	"github.com/sirupsen/logrus"
+
+	"github.com/l3uddz/tqm/httputils"
+	"github.com/l3uddz/tqm/logger"
+)
+
+type HDBConfig struct {
+	Username string `koanf:"username"`
+	Passkey  string `koanf:"passkey"`
+
+	RateLimitConfig `koanf:",squash"`
+}
+
+type HDB struct {
+	cfg  HDBConfig
+	http *http.Client
+	log  *logrus.Entry
+}
+
+func NewHDB(c HDBConfig) *HDB {
+	l := logger.GetLogger("hdb-api")
+	return &HDB{
+		cfg:  c,
+		http: httputils.NewRetryableHttpClient(15*time.Second, c.limiter(1, time.Second), l),
+		log:  l,
+	}
+}
+
+func (c *HDB) Name() string {
+	return "HDB"
+}
+
+func (c *HDB) Hosts() []string {
+	return []string{"hdbits.org"}
+}
+
+func (c *HDB) Check(host string) bool {
+	return matchesHost(host, c.Hosts())
+}
+
+func (c *HDB) IsUnregistered(ctx context.Context, torrent *Torrent) (error, bool) {
+	type Request struct {
+		Username string `json:"username"`
+		Passkey  string `json:"passkey"`
+		Hash     string `json:"hash"`
+	}
+
+	type Response struct {
+		Status  int    `json:"status"`
+		Message string `json:"message"`
+		Data    []struct {
+			Id   int    `json:"id"`
+			Hash string `json:"hash"`
+			Name string `json:"name"`
+		} `json:"data"`
+	}
+
+	// prepare request
+	payload := &Request{
+		Username: c.cfg.Username,
+		Passkey:  c.cfg.Passkey,
+		Hash:     torrent.Hash,
+	}
+
+	// send request
+	resp, err := rek.Post("https://hdbits.org/api/torrents", rek.Client(c.http), rek.Context(ctx), rek.Json(payload))
+	if err != nil {
+		c.log.WithError(err).Errorf("Failed searching for %s (hash: %s)", torrent.Name, torrent.Hash)
+		return fmt.Errorf("hdb: request search: %w", err), false
+	}
+	defer resp.Body().Close()
+
+	// validate response
+	if resp.StatusCode() != 200 {
+		c.log.WithError(err).Errorf("Failed validating search response for %s (hash: %s), response: %s",
+			torrent.Name, torrent.Hash, resp.Status())
+		return fmt.Errorf("hdb: validate search response: %s", resp.Status()), false
+	}
+
+	// decode response
+	b := new(Response)
+	if err := json.NewDecoder(resp.Body()).Decode(b); err != nil {
+		c.log.WithError(err).Errorf("Failed decoding search response for %s (hash: %s)",
+			torrent.Name, torrent.Hash)
+		return fmt.Errorf("hdb: decode search response: %w", err), false
+	}
+
+	// status 0 indicates success
+	if b.Status != 0 {
+		c.log.Errorf("Failed searching for %s (hash: %s), response: %d: %s", torrent.Name, torrent.Hash,
+			b.Status, b.Message)
+		return fmt.Errorf("hdb: search response: %d: %s", b.Status, b.Message), false
+	}
+
+	return nil, len(b.Data) < 1
+}
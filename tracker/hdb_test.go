@@ -0,0 +1,97 @@
+package tracker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// testTransport sends the requests to the test server instead of the tracker
+type testTransport struct {
+	url *url.URL
+}
+
+func (t testTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.URL.Scheme = t.url.Scheme
+	r.URL.Host = t.url.Host
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func newTestServer(t *testing.T, status int, body string) *url.URL {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func TestHDBIsUnregistered(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           int
+		body             string
+		wantUnregistered bool
+		wantErr          bool
+	}{
+		{name: "found", status: 200, body: `{"status":0,"data":[{"id":1,"hash":"abc"}]}`},
+		{name: "not found", status: 200, body: `{"status":0,"data":[]}`, wantUnregistered: true},
+		{name: "auth failed", status: 200, body: `{"status":5,"message":"Invalid passkey"}`, wantErr: true},
+		{name: "server error", status: 502, body: ``, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewHDB(HDBConfig{Username: "user", Passkey: "passkey"})
+			c.http = &http.Client{Transport: testTransport{url: newTestServer(t, tt.status, tt.body)}}
+
+			err, unregistered := c.IsUnregistered(context.Background(), &Torrent{Hash: "abc"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("IsUnregistered() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if unregistered != tt.wantUnregistered {
+				t.Errorf("IsUnregistered() = %v, want %v", unregistered, tt.wantUnregistered)
+			}
+		})
+	}
+}
+
+func TestBTNIsUnregistered(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           int
+		body             string
+		wantUnregistered bool
+		wantErr          bool
+	}{
+		{name: "found", status: 200, body: `{"result":{"results":"1","torrents":{}}}`},
+		{name: "not found", status: 200, body: `{"result":{"results":"0","torrents":[]}}`, wantUnregistered: true},
+		{name: "api error", status: 200, body: `{"error":{"code":-32001,"message":"Invalid API Key"}}`,
+			wantErr: true},
+		{name: "no result", status: 200, body: `{}`, wantErr: true},
+		{name: "server error", status: 502, body: ``, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewBTN(BTNConfig{Key: "key"})
+			c.http = &http.Client{Transport: testTransport{url: newTestServer(t, tt.status, tt.body)}}
+
+			err, unregistered := c.IsUnregistered(context.Background(), &Torrent{Hash: "abc"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("IsUnregistered() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if unregistered != tt.wantUnregistered {
+				t.Errorf("IsUnregistered() = %v, want %v", unregistered, tt.wantUnregistered)
+			}
+		})
+	}
+}
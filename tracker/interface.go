@@ -1,7 +1,27 @@
 package tracker
 
+import (
+	"context"
+)
+
 type Interface interface {
 	Name() string
+	Hosts() []string
 	Check(string) bool
-	IsUnregistered(torrent *Torrent) (error, bool)
+	IsUnregistered(ctx context.Context, torrent *Torrent) (error, bool)
+}
+
+type MetadataInterface interface {
+	GetMetadata(ctx context.Context, torrent *Torrent) (*Metadata, error)
+}
+
+// SeedTimeInterface is implemented by trackers with a configured hit-and-run seed time
+type SeedTimeInterface interface {
+	MinSeedHours() float64
+}
+
+// SearchInterface is implemented by trackers deriving both the unregistered status and the metadata of a torrent
+// from a single search, so they are looked up and cached together
+type SearchInterface interface {
+	Search(ctx context.Context, torrent *Torrent) (*SearchResult, error)
 }
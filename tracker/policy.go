@@ -0,0 +1,31 @@
+package tracker
+
+import (
+	"strings"
+)
+
+type Policy struct {
+	Trackers          []string `validate:"required"`
+	MinSeedDays       float32  `koanf:"min_seed_days" validate:"min=0"`
+	MinRatio          float32  `koanf:"min_ratio" validate:"min=0"`
+	MaxSeedDays       float32  `koanf:"max_seed_days" validate:"min=0"`
+	HnRDays           float32  `koanf:"hnr_days" validate:"min=0"`
+	IgnoreIfFreeleech bool     `koanf:"ignore_if_freeleech"`
+}
+
+var (
+	policies []Policy
+)
+
+// GetPolicy returns the seeding policy for the canonical tracker name
+func GetPolicy(name string) *Policy {
+	for i, p := range policies {
+		for _, t := range p.Trackers {
+			if strings.EqualFold(t, name) {
+				return &policies[i]
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package tracker
+
+import (
+	"context"
+	"sync"
+)
+
+type PrefetchRequest struct {
+	Torrent      *Torrent
+	Unregistered bool
+	Metadata     bool
+}
+
+// Prefetch resolves the requested lookups concurrently across trackers, populating the cache.
+// Lookups for the same tracker are sent sequentially so its rate limit is honoured, remaining lookups are skipped
+// once the context is cancelled.
+func Prefetch(ctx context.Context, requests []PrefetchRequest) int {
+	// group requests by tracker
+	groups := make(map[Interface][]PrefetchRequest)
+	for _, r := range requests {
+		if tr := Get(r.Torrent.TrackerName, r.Torrent.TrackerHost); tr != nil {
+			groups[tr] = append(groups[tr], r)
+		}
+	}
+
+	// process trackers concurrently
+	var wg sync.WaitGroup
+	var mtx sync.Mutex
+	prefetched := 0
+
+	for tr, reqs := range groups {
+		wg.Add(1)
+
+		go func(tr Interface, reqs []PrefetchRequest) {
+			defer wg.Done()
+
+			mi, hasMetadata := tr.(MetadataInterface)
+			for _, r := range reqs {
+				if ctx.Err() != nil {
+					return
+				}
+
+				// errors are logged by the tracker and the lookup retried on evaluation
+				if r.Unregistered {
+					_, _ = tr.IsUnregistered(ctx, r.Torrent)
+				}
+				if r.Metadata && hasMetadata {
+					_, _ = mi.GetMetadata(ctx, r.Torrent)
+				}
+
+				mtx.Lock()
+				prefetched++
+				mtx.Unlock()
+			}
+		}(tr, reqs)
+	}
+
+	wg.Wait()
+	return prefetched
+}
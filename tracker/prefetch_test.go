@@ -0,0 +1,104 @@
+package tracker
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+type prefetchTracker struct {
+	name string
+	mtx  sync.Mutex
+	// lookups by kind
+	lookups map[string]int
+}
+
+func (p *prefetchTracker) Name() string { return p.name }
+
+func (p *prefetchTracker) Hosts() []string { return []string{p.name + ".example"} }
+
+func (p *prefetchTracker) Check(host string) bool { return matchesHost(host, p.Hosts()) }
+
+func (p *prefetchTracker) lookup(kind string) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.lookups[kind]++
+}
+
+func (p *prefetchTracker) IsUnregistered(context.Context, *Torrent) (error, bool) {
+	p.lookup("unregistered")
+	return nil, false
+}
+
+func (p *prefetchTracker) GetMetadata(context.Context, *Torrent) (*Metadata, error) {
+	p.lookup("metadata")
+	return nil, nil
+}
+
+func TestPrefetch(t *testing.T) {
+	tests := []struct {
+		name           string
+		cancelled      bool
+		wantPrefetched int
+		wantLookups    map[string]map[string]int
+	}{
+		{
+			name:           "prefetched",
+			wantPrefetched: 3,
+			wantLookups: map[string]map[string]int{
+				"a": {"unregistered": 2, "metadata": 1},
+				"b": {"metadata": 1},
+			},
+		},
+		{
+			name:        "cancelled",
+			cancelled:   true,
+			wantLookups: map[string]map[string]int{"a": {}, "b": {}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &prefetchTracker{name: "a", lookups: make(map[string]int)}
+			b := &prefetchTracker{name: "b", lookups: make(map[string]int)}
+			trackers = []Interface{a, b}
+			defer func() { trackers = nil }()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelled {
+				cancel()
+			}
+
+			prefetched := Prefetch(ctx, []PrefetchRequest{
+				{Torrent: &Torrent{Hash: "1", TrackerName: "a"}, Unregistered: true, Metadata: true},
+				{Torrent: &Torrent{Hash: "2", TrackerHost: "tracker.a.example"}, Unregistered: true},
+				{Torrent: &Torrent{Hash: "3", TrackerName: "b"}, Metadata: true},
+				// no tracker api
+				{Torrent: &Torrent{Hash: "4", TrackerName: "c"}, Unregistered: true},
+			})
+
+			if prefetched != tt.wantPrefetched {
+				t.Errorf("Prefetch() = %d, want %d", prefetched, tt.wantPrefetched)
+			}
+			for _, tr := range []*prefetchTracker{a, b} {
+				for kind, want := range tt.wantLookups[tr.name] {
+					if got := tr.lookups[kind]; got != want {
+						t.Errorf("tracker %s: %s lookups = %d, want %d", tr.name, kind, got, want)
+					}
+				}
+				if total(tr.lookups) != total(tt.wantLookups[tr.name]) {
+					t.Errorf("tracker %s: lookups = %v, want %v", tr.name, tr.lookups, tt.wantLookups[tr.name])
+				}
+			}
+		})
+	}
+}
+
+func total(m map[string]int) int {
+	n := 0
+	for _, v := range m {
+		n += v
+	}
+	return n
+}
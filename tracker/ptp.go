@@ -1,6 +1,7 @@
 package tracker
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -10,15 +11,17 @@ import (
 
 	"github.com/lucperkins/rek"
 	"github.com/sirupsen/logrus"
-	"go.uber.org/ratelimit"
 
 	"github.com/l3uddz/tqm/httputils"
 	"github.com/l3uddz/tqm/logger"
 )
 
 type PTPConfig struct {
-	User string `koanf:"api_user"`
-	Key  string `koanf:"api_key"`
+	User         string  `koanf:"api_user"`
+	Key          string  `koanf:"api_key"`
+	MinSeedHours float64 `koanf:"min_seed_hours"`
+
+	RateLimitConfig `koanf:",squash"`
 }
 
 type PTP struct {
@@ -32,7 +35,7 @@ func NewPTP(c PTPConfig) *PTP {
 	l := logger.GetLogger("ptp-api")
 	return &PTP{
 		cfg:  c,
-		http: httputils.NewRetryableHttpClient(15*time.Second, ratelimit.New(1, ratelimit.WithoutSlack), l),
+		http: httputils.NewRetryableHttpClient(15*time.Second, c.limiter(1, time.Second), l),
 		headers: map[string]string{
 			"ApiUser": c.User,
 			"ApiKey":  c.Key,
@@ -45,29 +48,96 @@ func (c *PTP) Name() string {
 	return "PTP"
 }
 
+func (c *PTP) Hosts() []string {
+	return []string{"passthepopcorn.me"}
+}
+
 func (c *PTP) Check(host string) bool {
-	return strings.Contains(host, "passthepopcorn.me")
+	return matchesHost(host, c.Hosts())
+}
+
+func (c *PTP) MinSeedHours() float64 {
+	return c.cfg.MinSeedHours
+}
+
+func (c *PTP) IsUnregistered(ctx context.Context, torrent *Torrent) (error, bool) {
+	r, err := c.Search(ctx, torrent)
+	if err != nil {
+		return err, false
+	}
+
+	return nil, r.Unregistered
+}
+
+func (c *PTP) GetMetadata(ctx context.Context, torrent *Torrent) (*Metadata, error) {
+	r, err := c.Search(ctx, torrent)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Metadata, nil
 }
 
-func (c *PTP) IsUnregistered(torrent *Torrent) (error, bool) {
-	type Response struct {
-		Result        string `json:"Result"`
-		ResultDetails string `json:"ResultDetails"`
+func (c *PTP) Search(ctx context.Context, torrent *Torrent) (*SearchResult, error) {
+	b, err := c.search(ctx, torrent)
+	if err != nil {
+		return nil, err
 	}
 
+	if b.Result == "ERROR" && b.ResultDetails == "Unregistered Torrent" {
+		return &SearchResult{Unregistered: true}, nil
+	}
+
+	// find torrent within the group
+	for _, t := range b.Torrents {
+		if !strings.EqualFold(t.InfoHash, torrent.Hash) {
+			continue
+		}
+
+		seeders, _ := t.Seeders.Int64()
+		leechers, _ := t.Leechers.Int64()
+		snatched, _ := t.Snatched.Int64()
+
+		return &SearchResult{
+			Metadata: &Metadata{
+				Seeders:   seeders,
+				Leechers:  leechers,
+				Snatched:  snatched,
+				Freeleech: t.FreeleechType != "",
+			},
+		}, nil
+	}
+
+	// torrent not found
+	return &SearchResult{}, nil
+}
+
+type ptpResponse struct {
+	Result        string `json:"Result"`
+	ResultDetails string `json:"ResultDetails"`
+	Torrents      []struct {
+		InfoHash      string      `json:"InfoHash"`
+		Seeders       json.Number `json:"Seeders"`
+		Leechers      json.Number `json:"Leechers"`
+		Snatched      json.Number `json:"Snatched"`
+		FreeleechType string      `json:"FreeleechType"`
+	} `json:"Torrents"`
+}
+
+func (c *PTP) search(ctx context.Context, torrent *Torrent) (*ptpResponse, error) {
 	// prepare request
 	reqURL, err := httputils.WithQuery("https://passthepopcorn.me/torrents.php", url.Values{
 		"infohash": []string{torrent.Hash},
 	})
 	if err != nil {
-		return fmt.Errorf("ptp: url parse: %w", err), false
+		return nil, fmt.Errorf("ptp: url parse: %w", err)
 	}
 
 	// send request
-	resp, err := rek.Get(reqURL, rek.Client(c.http), rek.Headers(c.headers))
+	resp, err := rek.Get(reqURL, rek.Client(c.http), rek.Context(ctx), rek.Headers(c.headers))
 	if err != nil {
 		c.log.WithError(err).Errorf("Failed searching for %s (hash: %s)", torrent.Name, torrent.Hash)
-		return fmt.Errorf("ptp: request search: %w", err), false
+		return nil, fmt.Errorf("ptp: request search: %w", err)
 	}
 	defer resp.Body().Close()
 
@@ -75,16 +145,16 @@ func (c *PTP) IsUnregistered(torrent *Torrent) (error, bool) {
 	if resp.StatusCode() != 200 {
 		c.log.WithError(err).Errorf("Failed validating search response for %s (hash: %s), response: %s",
 			torrent.Name, torrent.Hash, resp.Status())
-		return fmt.Errorf("ptp: validate search response: %s", resp.Status()), false
+		return nil, fmt.Errorf("ptp: validate search response: %s", resp.Status())
 	}
 
 	// decode response
-	b := new(Response)
+	b := new(ptpResponse)
 	if err := json.NewDecoder(resp.Body()).Decode(b); err != nil {
 		c.log.WithError(err).Errorf("Failed decoding search response for %s (hash: %s)",
 			torrent.Name, torrent.Hash)
-		return fmt.Errorf("ptp: decode search response: %w", err), false
+		return nil, fmt.Errorf("ptp: decode search response: %w", err)
 	}
 
-	return nil, b.Result == "ERROR" && b.ResultDetails == "Unregistered Torrent"
+	return b, nil
 }
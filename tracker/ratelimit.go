@@ -0,0 +1,32 @@
+package tracker
+
+import (
+	"time"
+
+	"go.uber.org/ratelimit"
+)
+
+type RateLimitConfig struct {
+	Limit  int           `koanf:"rate_limit" validate:"min=0"`
+	Period time.Duration `koanf:"rate_period" validate:"duration"`
+	Burst  int           `koanf:"rate_burst" validate:"min=0"`
+}
+
+func (c RateLimitConfig) limiter(defaultLimit int, defaultPeriod time.Duration) ratelimit.Limiter {
+	limit := c.Limit
+	if limit < 1 {
+		limit = defaultLimit
+	}
+
+	period := c.Period
+	if period <= 0 {
+		period = defaultPeriod
+	}
+
+	slack := ratelimit.WithoutSlack
+	if c.Burst > 0 {
+		slack = ratelimit.WithSlack(c.Burst)
+	}
+
+	return ratelimit.New(limit, ratelimit.Per(period), slack)
+}
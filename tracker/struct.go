@@ -1,8 +1,15 @@
 package tracker
 
 type Config struct {
-	BHD BHDConfig
-	PTP PTPConfig
+	BHD      BHDConfig
+	PTP      PTPConfig
+	HDB      HDBConfig
+	BTN      BTNConfig
+	Unit3D   map[string]Unit3DConfig  `koanf:"unit3d"`
+	Gazelle  map[string]GazelleConfig `koanf:"gazelle"`
+	Cache    CacheConfig
+	Aliases  []AliasConfig
+	Policies []Policy
 }
 
 type Torrent struct {
@@ -17,5 +24,19 @@ type Torrent struct {
 
 	// tracker
 	TrackerName   string
+	TrackerHost   string
 	TrackerStatus string
 }
+
+type Metadata struct {
+	Seeders   int64 `json:"seeders"`
+	Leechers  int64 `json:"leechers"`
+	Snatched  int64 `json:"snatched"`
+	Freeleech bool  `json:"freeleech"`
+}
+
+type SearchResult struct {
+	Unregistered bool
+	// metadata of the torrent, nil when the torrent was not found
+	Metadata *Metadata
+}
@@ -1,11 +1,30 @@
 package tracker
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
 var (
-	trackers []Interface
+	trackers     []Interface
+	trackerCache *cache
+
+	// trackers with built-in api support
+	builtinTrackers = []Interface{&BHD{}, &PTP{}, &HDB{}, &BTN{}}
 )
 
 func Init(cfg Config) error {
 	trackers = make([]Interface, 0)
+	aliases = cfg.Aliases
+	policies = cfg.Policies
+
+	// load cache
+	c, err := newCache(cfg.Cache)
+	if err != nil {
+		return fmt.Errorf("cache: %w", err)
+	}
+	trackerCache = c
 
 	// load trackers
 	if cfg.BHD.Key != "" {
@@ -14,14 +33,47 @@ func Init(cfg Config) error {
 	if cfg.PTP.User != "" && cfg.PTP.Key != "" {
 		trackers = append(trackers, NewPTP(cfg.PTP))
 	}
+	if cfg.HDB.Username != "" && cfg.HDB.Passkey != "" {
+		trackers = append(trackers, NewHDB(cfg.HDB))
+	}
+	if cfg.BTN.Key != "" {
+		trackers = append(trackers, NewBTN(cfg.BTN))
+	}
+
+	// load named trackers (sorted for consistent lookup order)
+	for _, name := range sortedNames(cfg.Unit3D) {
+		if c := cfg.Unit3D[name]; c.Url != "" && c.Key != "" {
+			trackers = append(trackers, NewUnit3D(name, c))
+		}
+	}
+	for _, name := range sortedNames(cfg.Gazelle) {
+		if c := cfg.Gazelle[name]; c.Url != "" && c.Key != "" {
+			trackers = append(trackers, NewGazelle(name, c))
+		}
+	}
+
+	// wrap trackers with the cache
+	for i, t := range trackers {
+		trackers[i] = &cachedTracker{
+			Interface: t,
+			cache:     trackerCache,
+		}
+	}
 
 	return nil
 }
 
-func Get(host string) Interface {
+func Get(name string, host string) Interface {
+	// find tracker by canonical name
+	for _, tracker := range trackers {
+		if strings.EqualFold(tracker.Name(), name) {
+			return tracker
+		}
+	}
+
 	// find tracker for this host
 	for _, tracker := range trackers {
-		if tracker.Check(host) {
+		if (host != "" && tracker.Check(host)) || tracker.Check(name) {
 			return tracker
 		}
 	}
@@ -30,6 +82,56 @@ func Get(host string) Interface {
 	return nil
 }
 
+// BuiltinName returns the built-in tracker api supporting the tracker name or host, or an empty string
+func BuiltinName(name string) string {
+	for _, t := range builtinTrackers {
+		if strings.EqualFold(t.Name(), name) || t.Check(strings.ToLower(name)) {
+			return t.Name()
+		}
+	}
+
+	return ""
+}
+
 func Loaded() int {
 	return len(trackers)
 }
+
+func SaveCache() error {
+	if trackerCache == nil {
+		return nil
+	}
+
+	return trackerCache.save()
+}
+
+func CacheStats() (int, int) {
+	if trackerCache == nil {
+		return 0, 0
+	}
+
+	trackerCache.mtx.Lock()
+	defer trackerCache.mtx.Unlock()
+	return trackerCache.hits, trackerCache.misses
+}
+
+// matchesHost returns whether the host contains one of the hosts (e.g. subdomains)
+func matchesHost(host string, hosts []string) bool {
+	for _, h := range hosts {
+		if strings.Contains(host, h) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedNames[T any](m map[string]T) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,72 @@
+package tracker
+
+import (
+	"testing"
+)
+
+func TestBuiltinName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "BHD", want: "BHD"},
+		{name: "ptp", want: "PTP"},
+		{name: "tracker.beyond-hd.me", want: "BHD"},
+		{name: "please.passthepopcorn.me", want: "PTP"},
+		{name: "HDBits.org", want: "HDB"},
+		{name: "landof.tv", want: "BTN"},
+		{name: "tracker.broadcasthe.net", want: "BTN"},
+		{name: "aither.cc", want: ""},
+		{name: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BuiltinName(tt.name); got != tt.want {
+				t.Errorf("BuiltinName(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuiltinTrackersCheckHosts(t *testing.T) {
+	// the hosts used by the linter must be matched by the trackers
+	for _, tr := range []Interface{NewBHD(BHDConfig{}), NewPTP(PTPConfig{}), NewHDB(HDBConfig{}), NewBTN(BTNConfig{})} {
+		if len(tr.Hosts()) == 0 {
+			t.Errorf("%s: no hosts", tr.Name())
+		}
+
+		for _, host := range tr.Hosts() {
+			if !tr.Check("tracker." + host) {
+				t.Errorf("%s: Check(%q) = false", tr.Name(), "tracker."+host)
+			}
+			if got := BuiltinName(host); got != tr.Name() {
+				t.Errorf("BuiltinName(%q) = %q, want %q", host, got, tr.Name())
+			}
+		}
+	}
+}
+
+func TestNamedTrackerHosts(t *testing.T) {
+	tests := []struct {
+		name    string
+		tracker Interface
+		host    string
+		want    bool
+	}{
+		{name: "unit3d default domain", tracker: NewUnit3D("aither", Unit3DConfig{Url: "https://aither.cc"}),
+			host: "tracker.aither.cc", want: true},
+		{name: "unit3d domains", tracker: NewUnit3D("blu", Unit3DConfig{Url: "https://blutopia.cc",
+			Domains: []string{"blutopia.xyz"}}), host: "blutopia.cc", want: false},
+		{name: "gazelle domains", tracker: NewGazelle("red", GazelleConfig{Url: "https://redacted.sh",
+			Domains: []string{"flacsfor.me"}}), host: "flacsfor.me", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tracker.Check(tt.host); got != tt.want {
+				t.Errorf("Check(%q) = %v, want %v", tt.host, got, tt.want)
+			}
+		})
+	}
+}
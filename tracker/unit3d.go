@@ -0,0 +1,155 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/lucperkins/rek"
+	"github.com/sirupsen/logrus"
+
+	"github.com/l3uddz/tqm/httputils"
+	"github.com/l3uddz/tqm/logger"
+)
+
+type Unit3DConfig struct {
+	Url          string   `koanf:"base_url" validate:"url"`
+	Key          string   `koanf:"api_token"`
+	Domains      []string `koanf:"domains"`
+	MinSeedHours float64  `koanf:"min_seed_hours"`
+
+	RateLimitConfig `koanf:",squash"`
+}
+
+type Unit3D struct {
+	name string
+	cfg  Unit3DConfig
+	http *http.Client
+	log  *logrus.Entry
+}
+
+func NewUnit3D(name string, c Unit3DConfig) *Unit3D {
+	l := logger.GetLogger(name + "-api")
+
+	// default to the host of the base url when no domains were set
+	if len(c.Domains) == 0 {
+		if u, err := url.Parse(c.Url); err == nil && u.Hostname() != "" {
+			c.Domains = []string{u.Hostname()}
+		}
+	}
+
+	return &Unit3D{
+		name: name,
+		cfg:  c,
+		http: httputils.NewRetryableHttpClient(15*time.Second, c.limiter(1, time.Second), l),
+		log:  l,
+	}
+}
+
+func (c *Unit3D) Name() string {
+	return c.name
+}
+
+func (c *Unit3D) Hosts() []string {
+	return c.cfg.Domains
+}
+
+func (c *Unit3D) MinSeedHours() float64 {
+	return c.cfg.MinSeedHours
+}
+
+func (c *Unit3D) Check(host string) bool {
+	return matchesHost(host, c.Hosts())
+}
+
+func (c *Unit3D) IsUnregistered(ctx context.Context, torrent *Torrent) (error, bool) {
+	r, err := c.Search(ctx, torrent)
+	if err != nil {
+		return err, false
+	}
+
+	return nil, r.Unregistered
+}
+
+func (c *Unit3D) GetMetadata(ctx context.Context, torrent *Torrent) (*Metadata, error) {
+	r, err := c.Search(ctx, torrent)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Metadata, nil
+}
+
+func (c *Unit3D) Search(ctx context.Context, torrent *Torrent) (*SearchResult, error) {
+	b, err := c.search(ctx, torrent)
+	if err != nil {
+		return nil, err
+	} else if len(b.Data) < 1 {
+		// torrent not found
+		return &SearchResult{Unregistered: true}, nil
+	}
+
+	a := b.Data[0].Attributes
+	return &SearchResult{
+		Metadata: &Metadata{
+			Seeders:   a.Seeders,
+			Leechers:  a.Leechers,
+			Snatched:  a.TimesCompleted,
+			Freeleech: a.Freeleech != "" && a.Freeleech != "0%",
+		},
+	}, nil
+}
+
+type unit3dResponse struct {
+	Data []struct {
+		Id         interface{} `json:"id"`
+		Attributes struct {
+			Name           string `json:"name"`
+			Seeders        int64  `json:"seeders"`
+			Leechers       int64  `json:"leechers"`
+			TimesCompleted int64  `json:"times_completed"`
+			Freeleech      string `json:"freeleech"`
+		} `json:"attributes"`
+	} `json:"data"`
+}
+
+func (c *Unit3D) search(ctx context.Context, torrent *Torrent) (*unit3dResponse, error) {
+	// prepare request
+	reqURL, err := httputils.WithQuery(httputils.Join(c.cfg.Url, "api/torrents/filter"), url.Values{
+		"infoHash":  []string{torrent.Hash},
+		"api_token": []string{c.cfg.Key},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: url parse: %w", c.name, err)
+	}
+
+	// send request
+	resp, err := rek.Get(reqURL, rek.Client(c.http), rek.Context(ctx), rek.Headers(map[string]string{
+		"Accept": "application/json",
+	}))
+	if err != nil {
+		c.log.WithError(err).Errorf("Failed searching for %s (hash: %s)", torrent.Name, torrent.Hash)
+		return nil, fmt.Errorf("%s: request search: %w", c.name, err)
+	}
+	defer resp.Body().Close()
+
+	// validate response
+	if resp.StatusCode() != 200 {
+		c.log.WithError(err).Errorf("Failed validating search response for %s (hash: %s), response: %s",
+			torrent.Name, torrent.Hash, resp.Status())
+		return nil, fmt.Errorf("%s: validate search response: %s", c.name, resp.Status())
+	}
+
+	// decode response
+	b := new(unit3dResponse)
+	if err := json.NewDecoder(resp.Body()).Decode(b); err != nil {
+		c.log.WithError(err).Errorf("Failed decoding search response for %s (hash: %s)",
+			torrent.Name, torrent.Hash)
+		return nil, fmt.Errorf("%s: decode search response: %w", c.name, err)
+	}
+
+	return b, nil
+}
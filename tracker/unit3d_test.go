@@ -0,0 +1,60 @@
+package tracker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUnit3DSearch(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           int
+		body             string
+		wantUnregistered bool
+		wantMetadata     *Metadata
+		wantErr          bool
+	}{
+		{name: "not found", status: 200, body: `{"data":[]}`, wantUnregistered: true},
+		{name: "found", status: 200,
+			body:         `{"data":[{"id":1,"attributes":{"seeders":5,"leechers":1,"times_completed":10,"freeleech":"0%"}}]}`,
+			wantMetadata: &Metadata{Seeders: 5, Leechers: 1, Snatched: 10}},
+		{name: "freeleech", status: 200, body: `{"data":[{"id":1,"attributes":{"freeleech":"100%"}}]}`,
+			wantMetadata: &Metadata{Freeleech: true}},
+		{name: "unauthorized", status: 401, body: `{"message":"Unauthenticated."}`, wantErr: true},
+		{name: "invalid json", status: 200, body: `<html>`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/torrents/filter" || r.URL.Query().Get("infoHash") != "abc" ||
+					r.URL.Query().Get("api_token") != "token" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			c := NewUnit3D("test", Unit3DConfig{Url: srv.URL, Key: "token", MinSeedHours: 72})
+			r, err := c.Search(context.Background(), &Torrent{Hash: "abc", Name: "test"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Search() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err != nil {
+				return
+			}
+
+			if r.Unregistered != tt.wantUnregistered {
+				t.Errorf("Search() unregistered = %v, want %v", r.Unregistered, tt.wantUnregistered)
+			}
+			if (r.Metadata == nil) != (tt.wantMetadata == nil) ||
+				(r.Metadata != nil && *r.Metadata != *tt.wantMetadata) {
+				t.Errorf("Search() metadata = %+v, want %+v", r.Metadata, tt.wantMetadata)
+			}
+		})
+	}
+}